@@ -15,47 +15,106 @@ import (
 	"github.com/segmentio/kafka-go"
 	"github.com/tomiwa-a/Relay/internal/api/app"
 	"github.com/tomiwa-a/Relay/internal/api/routes"
+	"github.com/tomiwa-a/Relay/internal/executor"
+	"github.com/tomiwa-a/Relay/internal/idempotency"
+	"github.com/tomiwa-a/Relay/internal/outbox"
+	"github.com/tomiwa-a/Relay/internal/queue"
+	"github.com/tomiwa-a/Relay/internal/scheduler"
 	"github.com/tomiwa-a/Relay/internal/worker"
 )
 
 func main() {
 
-	config := app.LoadConfig()
 	logger := log.New(os.Stdout, "", log.Ldate|log.Ltime)
 
+	config, err := app.LoadConfig()
+	if err != nil {
+		logger.Fatalf("invalid configuration: %v", err)
+	}
+
 	db, err := app.OpenDB(config.DB)
 	if err != nil {
 		logger.Fatalf("failed to connect to database: %v", err)
 	}
 	defer db.Close()
 
-	redisClient := redis.NewClient(&redis.Options{
-		Addr: config.Redis.Addr,
-	})
-	defer redisClient.Close()
-
-	kafkaWriter := &kafka.Writer{
-		Addr:     kafka.TCP(config.Kafka.Brokers...),
-		Topic:    config.Kafka.Topic,
-		Balancer: &kafka.LeastBytes{},
+	var redisClient *redis.Client
+	if config.Redis.Addr != "" {
+		redisClient = redis.NewClient(&redis.Options{
+			Addr: config.Redis.Addr,
+		})
+		defer redisClient.Close()
+	} else if config.Queue.Backend == queue.BackendKafka {
+		logger.Fatal("redis address is required with the kafka queue backend")
 	}
 
-	defer kafkaWriter.Close()
+	var publisher queue.Publisher
 
-	application := app.NewApplication(config, logger, db, kafkaWriter, redisClient)
+	switch config.Queue.Backend {
+	case queue.BackendKafka:
+		kafkaWriter := &kafka.Writer{
+			Addr:                   kafka.TCP(config.Kafka.Brokers...),
+			Balancer:               &kafka.LeastBytes{},
+			AllowAutoTopicCreation: true,
+		}
+		defer kafkaWriter.Close()
 
-	kafkaReader := kafka.NewReader(kafka.ReaderConfig{
-		Brokers: config.Kafka.Brokers,
-		Topic:   config.Kafka.Topic,
-		GroupID: config.Kafka.GroupID,
-	})
-	defer kafkaReader.Close()
+		publisher = &queue.KafkaPublisher{Writer: kafkaWriter, Topic: config.Kafka.Topic}
+	case queue.BackendPostgres:
+		publisher = &queue.PostgresPublisher{}
+	default:
+		logger.Fatalf("unknown queue backend: %s", config.Queue.Backend)
+	}
 
-	backgroundWorker := worker.NewWorker(application, kafkaReader)
+	application := app.NewApplication(config, logger, db, publisher, redisClient)
 
 	workerCtx, cancelWorker := context.WithCancel(context.Background())
 	defer cancelWorker()
-	go backgroundWorker.Start(workerCtx)
+
+	go outbox.NewRelay(application).Start(workerCtx)
+	go worker.NewReaper(application).Start(workerCtx)
+	go scheduler.NewScheduler(application).Start(workerCtx)
+	go scheduler.NewCron(application).Start(workerCtx)
+	go idempotency.NewCleaner(application).Start(workerCtx)
+
+	workerDone := make(chan struct{})
+	if config.Worker.Enabled {
+		if len(config.Worker.Queues) == 0 {
+			logger.Fatal("the worker needs at least one queue to consume")
+		}
+
+		consumers := make(map[string]queue.Consumer, len(config.Worker.Queues))
+		for name := range config.Worker.Queues {
+			if !queue.ValidQueueName(name) {
+				logger.Fatalf("invalid queue name: %q", name)
+			}
+
+			// Jobs of other types are left to embedded workers
+			if config.Queue.Backend == queue.BackendPostgres {
+				consumers[name] = &queue.PostgresConsumer{
+					Repository:   application.Repository,
+					PollInterval: config.Queue.PollInterval,
+					Queue:        name,
+					JobTypes:     executor.BuiltinTypes,
+				}
+				continue
+			}
+
+			kafkaConsumer := &queue.KafkaConsumer{
+				Readers: queue.NewKafkaReaders(config.Kafka.Brokers, config.Kafka.Topic, name, config.Kafka.GroupID, false),
+			}
+			defer kafkaConsumer.Close()
+			consumers[name] = kafkaConsumer
+		}
+
+		backgroundWorker := worker.NewWorker(application, consumers)
+		go func() {
+			backgroundWorker.Start(workerCtx)
+			close(workerDone)
+		}()
+	} else {
+		close(workerDone)
+	}
 
 	r := gin.Default()
 
@@ -88,6 +147,9 @@ func main() {
 	<-quit
 	logger.Println("shutting down server...")
 
+	// Stop fetching new jobs; running jobs drain while the HTTP server shuts down
+	cancelWorker()
+
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
@@ -95,5 +157,7 @@ func main() {
 		logger.Printf("server forced to shutdown: %v", err)
 	}
 
+	<-workerDone
+
 	logger.Println("server stopped")
 }
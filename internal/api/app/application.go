@@ -1,28 +1,50 @@
 package app
 
 import (
+	"context"
 	"log"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/redis/go-redis/v9"
-	"github.com/segmentio/kafka-go"
+	"github.com/tomiwa-a/Relay/internal/executor"
+	"github.com/tomiwa-a/Relay/internal/queue"
 	"github.com/tomiwa-a/Relay/internal/repository"
 )
 
+// JobCancelChannel is the Redis pub/sub channel that tells workers to cancel a running job
+const JobCancelChannel = "job:cancel"
+
 type Application struct {
-	Config      Config
-	Logger      *log.Logger
-	Repository  *repository.Queries
-	KafkaWriter *kafka.Writer
-	Redis       *redis.Client
+	Config     Config
+	Logger     *log.Logger
+	DB         *pgxpool.Pool
+	Repository *repository.Queries
+	Queue      queue.Publisher
+	Redis      *redis.Client // nil when running the postgres queue without Redis
+	Executors  *executor.Registry
 }
 
-func NewApplication(config Config, logger *log.Logger, db *pgxpool.Pool, kafkaWriter *kafka.Writer, redisClient *redis.Client) *Application {
+func NewApplication(config Config, logger *log.Logger, db *pgxpool.Pool, publisher queue.Publisher, redisClient *redis.Client) *Application {
+	executors := executor.NewDefaultRegistry()
+	for _, jobType := range config.Executor.RemoteTypes {
+		executors.Register(jobType, &executor.RemoteExecutor{})
+	}
+
 	return &Application{
-		Config:      config,
-		Logger:      logger,
-		Repository:  repository.New(db),
-		KafkaWriter: kafkaWriter,
-		Redis:       redisClient,
+		Config:     config,
+		Logger:     logger,
+		DB:         db,
+		Repository: repository.New(db),
+		Queue:      publisher,
+		Redis:      redisClient,
+		Executors:  executors,
 	}
 }
+
+// InTx runs fn with queries bound to a single transaction, committing if fn returns nil
+func (app *Application) InTx(ctx context.Context, fn func(q *repository.Queries) error) error {
+	return pgx.BeginFunc(ctx, app.DB, func(tx pgx.Tx) error {
+		return fn(app.Repository.WithTx(tx))
+	})
+}
@@ -2,7 +2,10 @@ package app
 
 import (
 	"flag"
+	"fmt"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -25,13 +28,51 @@ type Config struct {
 		Topic   string
 		GroupID string
 	}
+	Queue struct {
+		Backend      string
+		PollInterval time.Duration
+	}
+	Outbox struct {
+		PollInterval time.Duration
+	}
+	Worker struct {
+		Enabled           bool
+		ID                string // recorded on job attempts, defaults to hostname-pid
+		Concurrency       int
+		Queues            map[string]int // queues to consume and the jobs each runs at the same time
+		DrainTimeout      time.Duration
+		HeartbeatInterval time.Duration
+	}
+	Reaper struct {
+		Interval   time.Duration
+		StaleAfter time.Duration
+	}
+	Scheduler struct {
+		PollInterval time.Duration
+	}
+	Idempotency struct {
+		Window time.Duration
+	}
+	Executor struct {
+		RemoteTypes []string
+	}
 }
 
-func LoadConfig() Config {
+// LoadConfig reads the configuration from flags and environment variables. It
+// returns an error for settings that can't be used, rather than falling back
+// to defaults.
+func LoadConfig() (Config, error) {
 
 	var config Config
 
 	var kafkaBrokers string
+	var remoteTypes string
+	var workerQueues string
+
+	workerConcurrency, err := getEnvInt("RELAY_WORKER_CONCURRENCY", 1)
+	if err != nil {
+		return Config{}, err
+	}
 
 	flag.IntVar(&config.Port, "port", 4000, "API server port number")
 	flag.StringVar(&config.Env, "env", "development", "Environment (development|staging|production)")
@@ -44,15 +85,75 @@ func LoadConfig() Config {
 	flag.StringVar(&config.Kafka.Topic, "kafka-topic", getEnv("RELAY_KAFKA_TOPIC", "relay-jobs"), "Kafka topic")
 	flag.StringVar(&config.Kafka.GroupID, "kafka-group-id", getEnv("RELAY_KAFKA_GROUP_ID", "relay-worker-group"), "Kafka consumer group ID")
 
-	flag.StringVar(&config.Redis.Addr, "redis-addr", getEnv("RELAY_REDIS_ADDR", "localhost:6379"), "Redis address")
+	flag.StringVar(&config.Queue.Backend, "queue-backend", getEnv("RELAY_QUEUE_BACKEND", "kafka"), "Queue backend (kafka|postgres)")
+	flag.DurationVar(&config.Queue.PollInterval, "queue-poll-interval", time.Second, "How often the postgres queue backend polls for pending jobs")
+
+	flag.DurationVar(&config.Outbox.PollInterval, "outbox-poll-interval", 500*time.Millisecond, "How often unsent outbox messages are published to the queue")
+
+	flag.StringVar(&config.Redis.Addr, "redis-addr", getEnv("RELAY_REDIS_ADDR", "localhost:6379"), "Redis address (may be empty with the postgres queue backend)")
 	flag.DurationVar(&config.Redis.LockTTL, "redis-lock-ttl", 10*time.Minute, "Redis lock TTL")
 	flag.BoolVar(&config.Redis.UseWatchdog, "redis-use-watchdog", true, "Enable Redis lock watchdog")
 
+	flag.BoolVar(&config.Worker.Enabled, "worker", getEnv("RELAY_WORKER", "true") == "true", "Run the background worker in this process")
+	flag.StringVar(&config.Worker.ID, "worker-id", getEnv("RELAY_WORKER_ID", ""), "Name recorded on the job attempts this worker runs (defaults to hostname-pid)")
+	flag.IntVar(&config.Worker.Concurrency, "worker-concurrency", workerConcurrency, "Number of jobs a worker runs at the same time per queue, unless -worker-queues sets one")
+	flag.StringVar(&workerQueues, "worker-queues", getEnv("RELAY_WORKER_QUEUES", "default"), "Queues to consume, as name or name:concurrency (comma separated)")
+	flag.DurationVar(&config.Worker.DrainTimeout, "worker-drain-timeout", getEnvDuration("RELAY_WORKER_DRAIN_TIMEOUT", 30*time.Second), "How long running jobs may finish on shutdown before they are interrupted and re-queued")
+	flag.DurationVar(&config.Worker.HeartbeatInterval, "heartbeat-interval", 30*time.Second, "How often a running job records a heartbeat")
+	flag.DurationVar(&config.Reaper.Interval, "reaper-interval", time.Minute, "How often to look for orphaned in_progress jobs")
+	flag.DurationVar(&config.Reaper.StaleAfter, "reaper-stale-after", 5*time.Minute, "How long an in_progress job may go without a heartbeat before it is reaped")
+	flag.DurationVar(&config.Scheduler.PollInterval, "scheduler-poll-interval", time.Second, "How often scheduled jobs and retries are checked for being due")
+	flag.DurationVar(&config.Idempotency.Window, "idempotency-window", getEnvDuration("RELAY_IDEMPOTENCY_WINDOW", 24*time.Hour), "How long an idempotency key maps to the job it created")
+	flag.StringVar(&remoteTypes, "remote-job-types", getEnv("RELAY_REMOTE_JOB_TYPES", ""), "Job types accepted by the API but handled by embedded workers (comma separated)")
+
 	flag.Parse()
 
 	config.Kafka.Brokers = []string{kafkaBrokers}
 
-	return config
+	for _, jobType := range strings.Split(remoteTypes, ",") {
+		if jobType = strings.TrimSpace(jobType); jobType != "" {
+			config.Executor.RemoteTypes = append(config.Executor.RemoteTypes, jobType)
+		}
+	}
+
+	if config.Worker.Concurrency < 1 {
+		return Config{}, fmt.Errorf("invalid worker concurrency %d, must be at least 1", config.Worker.Concurrency)
+	}
+
+	queues, err := parseQueues(workerQueues, config.Worker.Concurrency)
+	if err != nil {
+		return Config{}, err
+	}
+	config.Worker.Queues = queues
+
+	return config, nil
+}
+
+// parseQueues reads a list like "default,emails:4". Queues without a
+// concurrency of their own get the worker's.
+func parseQueues(value string, concurrency int) (map[string]int, error) {
+	queues := make(map[string]int)
+	for _, entry := range strings.Split(value, ",") {
+		name, limit, hasLimit := strings.Cut(strings.TrimSpace(entry), ":")
+		if name = strings.TrimSpace(name); name == "" {
+			if hasLimit {
+				return nil, fmt.Errorf("invalid worker queue %q, missing the queue name", entry)
+			}
+			continue
+		}
+
+		queues[name] = concurrency
+		if !hasLimit {
+			continue
+		}
+
+		parsed, err := strconv.Atoi(strings.TrimSpace(limit))
+		if err != nil || parsed < 1 {
+			return nil, fmt.Errorf("invalid concurrency %q for queue %q, must be a whole number of at least 1", limit, name)
+		}
+		queues[name] = parsed
+	}
+	return queues, nil
 }
 
 func getEnv(key, defaultValue string) string {
@@ -61,3 +162,27 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvInt returns an error rather than the default if the variable is set
+// but not a whole number
+func getEnvInt(key string, defaultValue int) (int, error) {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue, nil
+	}
+
+	parsed, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q, must be a whole number", key, value)
+	}
+	return parsed, nil
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value, exists := os.LookupEnv(key); exists {
+		if parsed, err := time.ParseDuration(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
@@ -0,0 +1,60 @@
+package app
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestParseQueues(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    map[string]int
+		wantErr bool
+	}{
+		{name: "default concurrency", value: "default", want: map[string]int{"default": 2}},
+		{name: "own concurrency", value: "default,emails:8", want: map[string]int{"default": 2, "emails": 8}},
+		{name: "spaces", value: " default , emails : 8 ", want: map[string]int{"default": 2, "emails": 8}},
+		{name: "empty entries", value: "default,,", want: map[string]int{"default": 2}},
+		{name: "nothing", value: "", want: map[string]int{}},
+		{name: "not a number", value: "emails:abc", wantErr: true},
+		{name: "zero", value: "emails:0", wantErr: true},
+		{name: "negative", value: "emails:-3", wantErr: true},
+		{name: "missing concurrency", value: "emails:", wantErr: true},
+		{name: "missing name", value: ":4", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseQueues(tt.value, 2)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseQueues(%q) = %v, want an error", tt.value, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseQueues(%q) returned error: %v", tt.value, err)
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("parseQueues(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetEnvInt(t *testing.T) {
+	t.Setenv("RELAY_TEST_INT", " 4 ")
+	if got, err := getEnvInt("RELAY_TEST_INT", 1); err != nil || got != 4 {
+		t.Errorf("getEnvInt = %d, %v, want 4", got, err)
+	}
+
+	t.Setenv("RELAY_TEST_INT", "four")
+	if _, err := getEnvInt("RELAY_TEST_INT", 1); err == nil {
+		t.Error("getEnvInt returned no error for a value that isn't a number")
+	}
+
+	if got, err := getEnvInt("RELAY_TEST_UNSET", 1); err != nil || got != 1 {
+		t.Errorf("getEnvInt = %d, %v, want the default for an unset variable", got, err)
+	}
+}
@@ -0,0 +1,52 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/tomiwa-a/Relay/internal/repository"
+)
+
+// pauseCacheTTL bounds how long workers can act on a stale pause state if
+// Redis couldn't be updated after a pause or resume
+const pauseCacheTTL = 30 * time.Second
+
+func pauseCacheKey(scope repository.PauseScope, name string) string {
+	return fmt.Sprintf("pause:%s:%s", scope, name)
+}
+
+// Paused reports whether a queue or job type is paused. The state is cached in
+// Redis and read from Postgres when it isn't cached or Redis is unavailable.
+func (app *Application) Paused(ctx context.Context, scope repository.PauseScope, name string) (bool, error) {
+	if app.Redis != nil {
+		cached, err := app.Redis.Get(ctx, pauseCacheKey(scope, name)).Bool()
+		if err == nil {
+			return cached, nil
+		}
+		if !errors.Is(err, redis.Nil) {
+			app.Logger.Printf("error reading pause state of %s %q from redis: %v", scope, name, err)
+		}
+	}
+
+	paused, err := app.Repository.IsPaused(ctx, repository.IsPausedParams{Scope: scope, Name: name})
+	if err != nil {
+		return false, err
+	}
+
+	if app.Redis != nil {
+		_ = app.Redis.Set(ctx, pauseCacheKey(scope, name), paused, pauseCacheTTL).Err()
+	}
+	return paused, nil
+}
+
+// CachePause stores a pause state in Redis once it is committed to Postgres,
+// so workers see it without waiting for the cached state to expire
+func (app *Application) CachePause(ctx context.Context, scope repository.PauseScope, name string, paused bool) error {
+	if app.Redis == nil {
+		return nil
+	}
+	return app.Redis.Set(ctx, pauseCacheKey(scope, name), paused, pauseCacheTTL).Err()
+}
@@ -1,20 +1,44 @@
 package controllers
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
-	"github.com/segmentio/kafka-go"
 	"github.com/tomiwa-a/Relay/internal/api/app"
+	"github.com/tomiwa-a/Relay/internal/api/customerrors"
+	"github.com/tomiwa-a/Relay/internal/chain"
+	"github.com/tomiwa-a/Relay/internal/queue"
 	"github.com/tomiwa-a/Relay/internal/repository"
+	"github.com/tomiwa-a/Relay/internal/retry"
 )
 
 func GetAllJobs(application *app.Application) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		jobs, err := application.Repository.ListJobs(c.Request.Context())
+		var filter repository.ListJobsParams
+		if value := c.Query("priority"); value != "" {
+			p, err := parsePriority(value)
+			if err != nil {
+				customerrors.FailedValidationResponse(c, map[string]string{"priority": err.Error()})
+				return
+			}
+			filter.Priority = repository.NullJobPriority{JobPriority: p, Valid: true}
+		}
+		if value := c.Query("queue"); value != "" {
+			filter.Queue = pgtype.Text{String: value, Valid: true}
+		}
+
+		jobs, err := application.Repository.ListJobs(c.Request.Context(), filter)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch jobs"})
 			return
@@ -27,6 +51,21 @@ func GetAllJobs(application *app.Application) gin.HandlerFunc {
 	}
 }
 
+func GetScheduledJobs(application *app.Application) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		jobs, err := application.Repository.ListScheduledJobs(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch scheduled jobs"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "scheduled jobs fetched successfully",
+			"data":    jobs,
+		})
+	}
+}
+
 func GetSingleJob(application *app.Application) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		{
@@ -36,7 +75,8 @@ func GetSingleJob(application *app.Application) gin.HandlerFunc {
 				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid job ID"})
 				return
 			}
-			job, err := application.Repository.GetJob(c.Request.Context(), int32(jobIDInt))
+
+			job, err := jobDetail(c.Request.Context(), application.Repository, int32(jobIDInt))
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch job"})
 				return
@@ -58,48 +98,79 @@ func AddJob(application *app.Application) gin.HandlerFunc {
 			return
 		}
 
-		parentID := pgtype.Int4{}
-		if req.ParentJobID != nil {
-			parentID = pgtype.Int4{Int32: *req.ParentJobID, Valid: true}
+		params, validationErrors := jobParams(application, req, "")
+		if validationErrors != nil {
+			customerrors.FailedValidationResponse(c, validationErrors)
+			return
 		}
-
-		description := pgtype.Text{}
-		if req.Description != "" {
-			description = pgtype.Text{String: req.Description, Valid: true}
+		if err := checkReferences(req.Payload, false, nil); err != nil {
+			customerrors.FailedValidationResponse(c, map[string]string{"payload": err.Error()})
+			return
 		}
 
-		maxRetries := pgtype.Int4{Int32: 3, Valid: true}
-		if req.MaxRetries > 0 {
-			maxRetries = pgtype.Int4{Int32: req.MaxRetries, Valid: true}
+		key := req.IdempotencyKey
+		if header := c.GetHeader("Idempotency-Key"); header != "" {
+			if key != "" && key != header {
+				customerrors.FailedValidationResponse(c, map[string]string{"idempotency_key": "does not match the Idempotency-Key header"})
+				return
+			}
+			key = header
 		}
-
-		timeoutSeconds := pgtype.Int4{Int32: 30, Valid: true} // Default 30 seconds
-		if req.TimeoutSeconds > 0 {
-			timeoutSeconds = pgtype.Int4{Int32: req.TimeoutSeconds, Valid: true}
+		if len(key) > maxIdempotencyKeyLength {
+			customerrors.FailedValidationResponse(c, map[string]string{"idempotency_key": fmt.Sprintf("must be at most %d characters", maxIdempotencyKeyLength)})
+			return
 		}
 
-		job, err := application.Repository.CreateJob(c.Request.Context(), repository.CreateJobParams{
-			ParentJobID:    parentID,
-			Title:          req.Title,
-			Description:    description,
-			Payload:        req.Payload,
-			MaxRetries:     maxRetries,
-			TimeoutSeconds: timeoutSeconds,
+		// The outbox message is written with the job so it can't be lost if the queue is down
+		var job JobDetail
+		replayed, merged := false, false
+		err := application.InTx(c.Request.Context(), func(q *repository.Queries) error {
+			if key != "" {
+				existing, err := claimIdempotencyKey(c.Request.Context(), q, application, params.Queue, key, req)
+				if err != nil {
+					return err
+				}
+				if existing != nil {
+					job, replayed = *existing, true
+					return nil
+				}
+			}
+
+			var err error
+			job, merged, err = submitJob(c.Request.Context(), q, application, req, params)
+			if err != nil || key == "" {
+				return err
+			}
+
+			return q.SetIdempotencyKeyJob(c.Request.Context(), repository.SetIdempotencyKeyJobParams{
+				Queue: params.Queue,
+				Key:   key,
+				JobID: pgtype.Int4{Int32: job.ID, Valid: true},
+			})
 		})
 
+		if errors.Is(err, errIdempotencyKeyReused) || errors.Is(err, errUniqueKeyActive) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create job"})
 			return
 		}
 
-		msg := kafka.Message{
-			Key:   []byte(strconv.Itoa(int(job.ID))),
-			Value: []byte(strconv.Itoa(int(job.ID))),
+		if replayed {
+			c.JSON(http.StatusOK, gin.H{
+				"message": "job already created with this idempotency key",
+				"data":    job,
+			})
+			return
 		}
-
-		err = application.KafkaWriter.WriteMessages(c.Request.Context(), msg)
-		if err != nil {
-			application.Logger.Printf("failed to push job [%d] to kafka: %v", job.ID, err)
+		if merged {
+			c.JSON(http.StatusOK, gin.H{
+				"message": "job merged into the active job with the same unique key",
+				"data":    job,
+			})
+			return
 		}
 
 		c.JSON(http.StatusCreated, gin.H{
@@ -109,6 +180,338 @@ func AddJob(application *app.Application) gin.HandlerFunc {
 	}
 }
 
+const maxIdempotencyKeyLength = 255
+
+var errIdempotencyKeyReused = errors.New("idempotency key was already used for a different request")
+
+// claimIdempotencyKey reserves key in the job's queue for the request being
+// created. If the key is still held by an earlier identical request, the job
+// that request created is returned instead. Concurrent requests with the same
+// key wait on each other.
+func claimIdempotencyKey(ctx context.Context, q *repository.Queries, application *app.Application, jobQueue, key string, req CreateJobRequest) (*JobDetail, error) {
+	// The key itself is left out so the header and the field hash the same
+	req.IdempotencyKey = ""
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256(body)
+	requestHash := hex.EncodeToString(hash[:])
+
+	now := time.Now().UTC()
+	_, err = q.ClaimIdempotencyKey(ctx, repository.ClaimIdempotencyKeyParams{
+		Queue:       jobQueue,
+		Key:         key,
+		RequestHash: requestHash,
+		ExpiresAt:   pgtype.Timestamp{Time: now.Add(application.Config.Idempotency.Window), Valid: true},
+		Now:         pgtype.Timestamp{Time: now, Valid: true},
+	})
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+
+	existing, err := q.GetIdempotencyKey(ctx, repository.GetIdempotencyKeyParams{Queue: jobQueue, Key: key})
+	if err != nil {
+		return nil, err
+	}
+	if existing.RequestHash != requestHash {
+		return nil, errIdempotencyKeyReused
+	}
+
+	job, err := jobDetail(ctx, q, existing.JobID.Int32)
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+var errUniqueKeyActive = errors.New("a job with this unique key is already active")
+
+// submitJob creates a job, first applying its unique policy if another job with
+// the same unique key is active. A merged submission returns the active job
+// instead. Submissions with the same key are serialised by a transaction lock.
+func submitJob(ctx context.Context, q *repository.Queries, application *app.Application, req CreateJobRequest, params repository.CreateJobParams) (JobDetail, bool, error) {
+	if req.UniqueKey == "" {
+		job, err := createJob(ctx, q, application, req, params)
+		return job, false, err
+	}
+
+	if err := q.AdvisoryXactLockKey(ctx, "unique_key:"+req.UniqueKey); err != nil {
+		return JobDetail{}, false, err
+	}
+
+	active, err := q.GetActiveJobByUniqueKey(ctx, params.UniqueKey)
+	if errors.Is(err, pgx.ErrNoRows) {
+		job, err := createJob(ctx, q, application, req, params)
+		return job, false, err
+	}
+	if err != nil {
+		return JobDetail{}, false, err
+	}
+
+	switch req.UniquePolicy {
+	case uniquePolicyMerge:
+		job, err := jobDetail(ctx, q, active.ID)
+		return job, true, err
+	case uniquePolicyReplace:
+		// A running job can't be replaced; CancelPendingJob finds no rows for it
+		replaced, err := q.CancelPendingJob(ctx, active.ID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return JobDetail{}, false, fmt.Errorf("%w: job [%d] is already running and can't be replaced", errUniqueKeyActive, active.ID)
+		}
+		if err != nil {
+			return JobDetail{}, false, err
+		}
+		if err := chain.Settle(ctx, q, replaced, repository.JobStatusCancelled); err != nil {
+			return JobDetail{}, false, err
+		}
+
+		job, err := createJob(ctx, q, application, req, params)
+		if err != nil {
+			return JobDetail{}, false, err
+		}
+
+		_, err = q.CreateJobLog(ctx, repository.CreateJobLogParams{
+			JobID:   active.ID,
+			Level:   repository.LogLevelWARN,
+			Message: fmt.Sprintf("job cancelled, replaced by job [%d] with the same unique key", job.ID),
+		})
+		return job, false, err
+	}
+
+	return JobDetail{}, false, fmt.Errorf("%w: job [%d]", errUniqueKeyActive, active.ID)
+}
+
+// jobDetail loads a job and the jobs chained to it
+func jobDetail(ctx context.Context, q *repository.Queries, jobID int32) (JobDetail, error) {
+	job, err := q.GetJob(ctx, jobID)
+	if err != nil {
+		return JobDetail{}, err
+	}
+
+	children, err := q.ListChildJobs(ctx, pgtype.Int4{Int32: job.ID, Valid: true})
+	if err != nil {
+		return JobDetail{}, err
+	}
+	if children == nil {
+		children = []repository.Job{}
+	}
+	return JobDetail{Job: job, Children: children}, nil
+}
+
+// jobParams validates a job request, including any chained jobs, and fills in
+// the defaults. Error keys are prefixed with prefix for chained jobs.
+func jobParams(application *app.Application, req CreateJobRequest, prefix string) (repository.CreateJobParams, map[string]string) {
+	if _, err := application.Executors.Resolve(req.Payload); err != nil {
+		return repository.CreateJobParams{}, map[string]string{prefix + "payload": err.Error()}
+	}
+
+	parentID := pgtype.Int4{}
+	if req.ParentJobID != nil {
+		parentID = pgtype.Int4{Int32: *req.ParentJobID, Valid: true}
+	}
+
+	description := pgtype.Text{}
+	if req.Description != "" {
+		description = pgtype.Text{String: req.Description, Valid: true}
+	}
+
+	maxRetries := pgtype.Int4{Int32: 3, Valid: true}
+	if req.MaxRetries > 0 {
+		maxRetries = pgtype.Int4{Int32: req.MaxRetries, Valid: true}
+	}
+
+	timeoutSeconds := pgtype.Int4{Int32: 30, Valid: true} // Default 30 seconds
+	if req.TimeoutSeconds > 0 {
+		timeoutSeconds = pgtype.Int4{Int32: req.TimeoutSeconds, Valid: true}
+	}
+
+	policy := retry.Default()
+	if req.RetryPolicy != nil {
+		if req.RetryPolicy.Strategy != "" {
+			policy.Strategy = repository.RetryStrategy(req.RetryPolicy.Strategy)
+		}
+		if req.RetryPolicy.BaseDelaySeconds != 0 {
+			policy.BaseDelay = time.Duration(req.RetryPolicy.BaseDelaySeconds) * time.Second
+		}
+		policy.MaxDelay = time.Duration(req.RetryPolicy.MaxDelaySeconds) * time.Second
+		policy.Jitter = req.RetryPolicy.Jitter
+		policy.MaxDuration = time.Duration(req.RetryPolicy.MaxDurationSeconds) * time.Second
+	}
+	if err := policy.Validate(); err != nil {
+		return repository.CreateJobParams{}, map[string]string{prefix + "retry_policy": err.Error()}
+	}
+
+	if req.DelaySeconds < 0 {
+		return repository.CreateJobParams{}, map[string]string{prefix + "delay_seconds": "must not be negative"}
+	}
+	if req.RunAt != nil && req.DelaySeconds > 0 {
+		return repository.CreateJobParams{}, map[string]string{prefix + "run_at": "cannot be combined with delay_seconds"}
+	}
+
+	runAt := pgtype.Timestamp{}
+	if req.RunAt != nil {
+		runAt = pgtype.Timestamp{Time: req.RunAt.UTC(), Valid: true}
+	} else if req.DelaySeconds > 0 {
+		runAt = pgtype.Timestamp{Time: time.Now().UTC().Add(time.Duration(req.DelaySeconds) * time.Second), Valid: true}
+	}
+
+	uniqueKey := pgtype.Text{}
+	if req.UniqueKey != "" {
+		uniqueKey = pgtype.Text{String: req.UniqueKey, Valid: true}
+	}
+	switch req.UniquePolicy {
+	case "", uniquePolicyReject, uniquePolicyMerge, uniquePolicyReplace:
+	default:
+		return repository.CreateJobParams{}, map[string]string{prefix + "unique_policy": "must be one of reject, merge or replace"}
+	}
+	if req.UniquePolicy != "" && req.UniqueKey == "" {
+		return repository.CreateJobParams{}, map[string]string{prefix + "unique_policy": "requires unique_key"}
+	}
+
+	priority := repository.JobPriorityNormal
+	if req.Priority != "" {
+		p, err := parsePriority(req.Priority)
+		if err != nil {
+			return repository.CreateJobParams{}, map[string]string{prefix + "priority": err.Error()}
+		}
+		priority = p
+	}
+
+	jobQueue := queue.DefaultQueue
+	if req.Queue != "" {
+		if !queue.ValidQueueName(req.Queue) {
+			return repository.CreateJobParams{}, map[string]string{prefix + "queue": "must be 1 to 64 letters, digits or underscores"}
+		}
+		jobQueue = req.Queue
+	}
+
+	rateLimitKey := pgtype.Text{}
+	if req.RateLimitKey != "" {
+		rateLimitKey = pgtype.Text{String: req.RateLimitKey, Valid: true}
+	}
+
+	if req.ConcurrencyLimit < 0 {
+		return repository.CreateJobParams{}, map[string]string{prefix + "concurrency_limit": "must not be negative"}
+	}
+	if req.ConcurrencyLimit > 0 && req.ConcurrencyKey == "" {
+		return repository.CreateJobParams{}, map[string]string{prefix + "concurrency_limit": "requires concurrency_key"}
+	}
+	concurrencyKey := pgtype.Text{}
+	concurrencyLimit := pgtype.Int4{}
+	if req.ConcurrencyKey != "" {
+		concurrencyKey = pgtype.Text{String: req.ConcurrencyKey, Valid: true}
+		concurrencyLimit = pgtype.Int4{Int32: 1, Valid: true}
+		if req.ConcurrencyLimit > 0 {
+			concurrencyLimit.Int32 = req.ConcurrencyLimit
+		}
+	}
+
+	// Jobs due in the future wait for the scheduler instead of going straight to the queue
+	status := repository.JobStatusPending
+	if runAt.Valid && runAt.Time.After(time.Now().UTC()) {
+		status = repository.JobStatusScheduled
+	}
+
+	for _, child := range req.chained() {
+		key := prefix + string(child.trigger)
+		if child.spec.ParentJobID != nil || child.spec.RunAt != nil || child.spec.DelaySeconds != 0 ||
+			child.spec.IdempotencyKey != "" || child.spec.UniqueKey != "" {
+			return repository.CreateJobParams{}, map[string]string{key: "cannot set parent_job_id, run_at, delay_seconds, idempotency_key or unique_key"}
+		}
+		if err := checkReferences(child.spec.Payload, true, nil); err != nil {
+			return repository.CreateJobParams{}, map[string]string{key + ".payload": err.Error()}
+		}
+		if _, validationErrors := jobParams(application, *child.spec, key+"."); validationErrors != nil {
+			return repository.CreateJobParams{}, validationErrors
+		}
+	}
+
+	return repository.CreateJobParams{
+		ParentJobID:      parentID,
+		Title:            req.Title,
+		Description:      description,
+		Payload:          req.Payload,
+		MaxRetries:       maxRetries,
+		TimeoutSeconds:   timeoutSeconds,
+		Status:           repository.NullJobStatus{JobStatus: status, Valid: true},
+		RunAt:            runAt,
+		UniqueKey:        uniqueKey,
+		Priority:         priority,
+		Queue:            jobQueue,
+		RateLimitKey:     rateLimitKey,
+		ConcurrencyKey:   concurrencyKey,
+		ConcurrencyLimit: concurrencyLimit,
+		// Retry settings are stored in whole seconds
+		RetryStrategy:           policy.Strategy,
+		RetryBaseDelaySeconds:   int32(policy.BaseDelay / time.Second),
+		RetryMaxDelaySeconds:    pgtype.Int4{Int32: int32(policy.MaxDelay / time.Second), Valid: policy.MaxDelay > 0},
+		RetryJitter:             policy.Jitter,
+		RetryMaxDurationSeconds: pgtype.Int4{Int32: int32(policy.MaxDuration / time.Second), Valid: policy.MaxDuration > 0},
+	}, nil
+}
+
+func parsePriority(value string) (repository.JobPriority, error) {
+	switch p := repository.JobPriority(value); p {
+	case repository.JobPriorityLow, repository.JobPriorityNormal, repository.JobPriorityHigh:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown priority %q, must be one of low, normal or high", value)
+}
+
+// checkReferences reports a payload template pointing at a result the job won't
+// have. Chained jobs can read their parent's result and workflow steps the
+// results of the steps they depend on.
+func checkReferences(payload json.RawMessage, chained bool, dependsOn []string) error {
+	refs, err := chain.References(payload)
+	if err != nil {
+		return err
+	}
+
+	for _, ref := range refs {
+		if ref.Step == "" && !chained {
+			return errors.New("parent.result can only be used in chained jobs")
+		}
+		if ref.Step != "" && !slices.Contains(dependsOn, ref.Step) {
+			return fmt.Errorf("steps.%s.result can only be used by steps that depend on %q", ref.Step, ref.Step)
+		}
+	}
+	return nil
+}
+
+// createJob stores a validated job and its chained jobs. Chained jobs wait
+// until their parent finishes.
+func createJob(ctx context.Context, q *repository.Queries, application *app.Application, req CreateJobRequest, params repository.CreateJobParams) (JobDetail, error) {
+	job, err := q.CreateJob(ctx, params)
+	if err != nil {
+		return JobDetail{}, err
+	}
+
+	if params.Status.JobStatus == repository.JobStatusPending {
+		if _, err := q.CreateOutboxMessage(ctx, job.ID); err != nil {
+			return JobDetail{}, err
+		}
+	}
+
+	detail := JobDetail{Job: job, Children: []repository.Job{}}
+	for _, child := range req.chained() {
+		childParams, _ := jobParams(application, *child.spec, "")
+		childParams.ParentJobID = pgtype.Int4{Int32: job.ID, Valid: true}
+		childParams.Status = repository.NullJobStatus{JobStatus: repository.JobStatusWaiting, Valid: true}
+		childParams.ChainTrigger = repository.NullChainTrigger{ChainTrigger: child.trigger, Valid: true}
+
+		childJob, err := createJob(ctx, q, application, *child.spec, childParams)
+		if err != nil {
+			return JobDetail{}, err
+		}
+		detail.Children = append(detail.Children, childJob.Job)
+	}
+	return detail, nil
+}
+
 func GetJobLogs(application *app.Application) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		jobID := c.Param("id")
@@ -131,6 +534,46 @@ func GetJobLogs(application *app.Application) gin.HandlerFunc {
 	}
 }
 
+func GetJobAttempts(application *app.Application) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		jobID := c.Param("id")
+		jobIDInt, err := strconv.Atoi(jobID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid job ID"})
+			return
+		}
+
+		attempts, err := application.Repository.ListJobAttempts(c.Request.Context(), int32(jobIDInt))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch job attempts"})
+			return
+		}
+
+		logs, err := application.Repository.GetJobLogs(c.Request.Context(), int32(jobIDInt))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch job logs"})
+			return
+		}
+
+		attemptLogs := make(map[int32][]repository.JobLog, len(attempts))
+		for _, log := range logs {
+			if log.AttemptID.Valid {
+				attemptLogs[log.AttemptID.Int32] = append(attemptLogs[log.AttemptID.Int32], log)
+			}
+		}
+
+		details := make([]AttemptDetail, 0, len(attempts))
+		for _, attempt := range attempts {
+			details = append(details, AttemptDetail{JobAttempt: attempt, Logs: attemptLogs[attempt.ID]})
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "job attempts fetched successfully",
+			"data":    details,
+		})
+	}
+}
+
 func ReplayJob(application *app.Application) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		jobID := c.Param("id")
@@ -146,24 +589,34 @@ func ReplayJob(application *app.Application) gin.HandlerFunc {
 			return
 		}
 
-		if job.Status.JobStatus != repository.JobStatusDead && job.Status.JobStatus != repository.JobStatusFailed {
+		switch job.Status.JobStatus {
+		case repository.JobStatusDead, repository.JobStatusFailed, repository.JobStatusCancelled, repository.JobStatusSkipped:
+		default:
 			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("cannot replay job with status: %s", job.Status.JobStatus)})
 			return
 		}
 
-		replayedJob, err := application.Repository.ReplayJob(c.Request.Context(), int32(jobIDInt))
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to replay job"})
+		var replayedJob repository.Job
+		err = application.InTx(c.Request.Context(), func(q *repository.Queries) error {
+			var err error
+			replayedJob, err = chain.Replay(c.Request.Context(), q, job)
+			return err
+		})
+		if errors.Is(err, chain.ErrDependenciesIncomplete) {
+			c.JSON(http.StatusConflict, gin.H{"error": "cannot replay job whose dependencies have not completed"})
 			return
 		}
-
-		// Push to Kafka
-		msg := kafka.Message{
-			Key:   []byte(strconv.Itoa(int(replayedJob.ID))),
-			Value: []byte(strconv.Itoa(int(replayedJob.ID))),
+		if errors.Is(err, chain.ErrUnfilledPayload) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": fmt.Sprintf("cannot replay job: %v", err)})
+			return
 		}
-		if err := application.KafkaWriter.WriteMessages(c.Request.Context(), msg); err != nil {
-			application.Logger.Printf("failed to push replayed job [%d] to kafka: %v", replayedJob.ID, err)
+		if isUniqueViolation(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": errUniqueKeyActive.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to replay job"})
+			return
 		}
 
 		c.JSON(http.StatusOK, gin.H{
@@ -172,3 +625,89 @@ func ReplayJob(application *app.Application) gin.HandlerFunc {
 		})
 	}
 }
+
+func CancelJob(application *app.Application) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		jobID := c.Param("id")
+		jobIDInt, err := strconv.Atoi(jobID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid job ID"})
+			return
+		}
+
+		// Jobs that haven't started yet are cancelled outright
+		var cancelledJob repository.Job
+		err = application.InTx(c.Request.Context(), func(q *repository.Queries) error {
+			var err error
+			cancelledJob, err = q.CancelPendingJob(c.Request.Context(), int32(jobIDInt))
+			if err != nil {
+				return err
+			}
+
+			_, err = q.CreateJobLog(c.Request.Context(), repository.CreateJobLogParams{
+				JobID:   cancelledJob.ID,
+				Level:   repository.LogLevelWARN,
+				Message: "job cancelled",
+			})
+			if err != nil {
+				return err
+			}
+			return chain.Settle(c.Request.Context(), q, cancelledJob, repository.JobStatusCancelled)
+		})
+		if err == nil {
+			c.JSON(http.StatusOK, gin.H{
+				"message": "job cancelled successfully",
+				"data":    cancelledJob,
+			})
+			return
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to cancel job"})
+			return
+		}
+
+		// Running jobs are flagged; the worker running it stops the process and
+		// marks it cancelled
+		err = application.InTx(c.Request.Context(), func(q *repository.Queries) error {
+			var err error
+			cancelledJob, err = q.RequestJobCancellation(c.Request.Context(), int32(jobIDInt))
+			if err != nil {
+				return err
+			}
+
+			_, err = q.CreateJobLog(c.Request.Context(), repository.CreateJobLogParams{
+				JobID:   cancelledJob.ID,
+				Level:   repository.LogLevelWARN,
+				Message: "job cancellation requested",
+			})
+			return err
+		})
+		if err == nil {
+			if application.Redis != nil {
+				// Workers also pick the flag up on their next heartbeat, so a lost
+				// notification only delays the cancellation
+				if err := application.Redis.Publish(c.Request.Context(), app.JobCancelChannel, strconv.Itoa(jobIDInt)).Err(); err != nil {
+					application.Logger.Printf("failed to publish cancellation for job %d: %v", jobIDInt, err)
+				}
+			}
+
+			c.JSON(http.StatusAccepted, gin.H{
+				"message": "job cancellation requested",
+				"data":    cancelledJob,
+			})
+			return
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to cancel job"})
+			return
+		}
+
+		job, err := application.Repository.GetJob(c.Request.Context(), int32(jobIDInt))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "job not found"})
+			return
+		}
+
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("cannot cancel job with status: %s", job.Status.JobStatus)})
+	}
+}
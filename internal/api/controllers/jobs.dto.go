@@ -1,6 +1,11 @@
 package controllers
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/tomiwa-a/Relay/internal/repository"
+)
 
 type CreateJobRequest struct {
 	ParentJobID    *int32          `json:"parent_job_id"`
@@ -9,6 +14,74 @@ type CreateJobRequest struct {
 	Payload        json.RawMessage `json:"payload" binding:"required"`
 	MaxRetries     int32           `json:"max_retries"`
 	TimeoutSeconds int32           `json:"timeout_seconds"`
+	RunAt          *time.Time      `json:"run_at"`
+	DelaySeconds   int32           `json:"delay_seconds"`
+	RetryPolicy    *RetryPolicy    `json:"retry_policy"`
+	// Also accepted as the Idempotency-Key header
+	IdempotencyKey string `json:"idempotency_key"`
+	// At most one job per unique key is active at a time. UniquePolicy decides
+	// what happens to a submission while one is: reject (default), merge or replace.
+	UniqueKey    string `json:"unique_key"`
+	UniquePolicy string `json:"unique_policy"`
+	// low, normal (default) or high. Higher priority jobs are picked up first.
+	Priority string `json:"priority"`
+	// Only workers consuming the queue run the job. Defaults to "default".
+	Queue string `json:"queue"`
+	// Groups jobs under a rate limit set for the key
+	RateLimitKey string `json:"rate_limit_key"`
+	// At most ConcurrencyLimit jobs sharing the key run at once. Defaults to 1.
+	ConcurrencyKey   string `json:"concurrency_key"`
+	ConcurrencyLimit int32  `json:"concurrency_limit"`
+
+	// Jobs chained to this one, started when it finishes
+	OnSuccess  *CreateJobRequest `json:"on_success"`
+	OnFailure  *CreateJobRequest `json:"on_failure"`
+	OnComplete *CreateJobRequest `json:"on_complete"`
+}
+
+const (
+	uniquePolicyReject  = "reject"
+	uniquePolicyMerge   = "merge"
+	uniquePolicyReplace = "replace"
+)
+
+type chainedJob struct {
+	trigger repository.ChainTrigger
+	spec    *CreateJobRequest
+}
+
+func (r CreateJobRequest) chained() []chainedJob {
+	var jobs []chainedJob
+	if r.OnSuccess != nil {
+		jobs = append(jobs, chainedJob{trigger: repository.ChainTriggerOnSuccess, spec: r.OnSuccess})
+	}
+	if r.OnFailure != nil {
+		jobs = append(jobs, chainedJob{trigger: repository.ChainTriggerOnFailure, spec: r.OnFailure})
+	}
+	if r.OnComplete != nil {
+		jobs = append(jobs, chainedJob{trigger: repository.ChainTriggerOnComplete, spec: r.OnComplete})
+	}
+	return jobs
+}
+
+// JobDetail is a job together with the jobs chained to it
+type JobDetail struct {
+	repository.Job
+	Children []repository.Job
+}
+
+// AttemptDetail is one execution of a job together with the logs it wrote
+type AttemptDetail struct {
+	repository.JobAttempt
+	Logs []repository.JobLog
+}
+
+type RetryPolicy struct {
+	Strategy           string `json:"strategy"`
+	BaseDelaySeconds   int32  `json:"base_delay_seconds"`
+	MaxDelaySeconds    int32  `json:"max_delay_seconds"`
+	Jitter             bool   `json:"jitter"`
+	MaxDurationSeconds int32  `json:"max_duration_seconds"`
 }
 
 type JobResponse struct {
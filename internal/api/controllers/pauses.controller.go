@@ -0,0 +1,148 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tomiwa-a/Relay/internal/api/app"
+	"github.com/tomiwa-a/Relay/internal/api/customerrors"
+	"github.com/tomiwa-a/Relay/internal/executor"
+	"github.com/tomiwa-a/Relay/internal/queue"
+	"github.com/tomiwa-a/Relay/internal/repository"
+)
+
+var errNotPaused = errors.New("not paused")
+
+func GetAllPauses(application *app.Application) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		pauses, err := application.Repository.ListPauses(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch pauses"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "pauses fetched successfully",
+			"data":    pauses,
+		})
+	}
+}
+
+func PauseQueue(application *app.Application) gin.HandlerFunc {
+	return pause(application, repository.PauseScopeQueue)
+}
+
+func ResumeQueue(application *app.Application) gin.HandlerFunc {
+	return resume(application, repository.PauseScopeQueue)
+}
+
+func PauseJobType(application *app.Application) gin.HandlerFunc {
+	return pause(application, repository.PauseScopeJobType)
+}
+
+func ResumeJobType(application *app.Application) gin.HandlerFunc {
+	return resume(application, repository.PauseScopeJobType)
+}
+
+// pause stops workers from starting jobs in a queue or of a job type. Jobs
+// already running carry on, and pending ones stay pending until the resume.
+func pause(application *app.Application, scope repository.PauseScope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name, ok := targetName(c, string(scope))
+		if !ok {
+			return
+		}
+
+		paused, err := application.Repository.CreatePause(c.Request.Context(), repository.CreatePauseParams{
+			Scope: scope,
+			Name:  name,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to pause " + scopeLabel(string(scope))})
+			return
+		}
+
+		// Postgres stays authoritative: workers re-check it when claiming a job
+		if err := application.CachePause(c.Request.Context(), scope, name, true); err != nil {
+			application.Logger.Printf("error caching pause of %s %q: %v", scope, name, err)
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": scopeLabel(string(scope)) + " paused successfully",
+			"data":    paused,
+		})
+	}
+}
+
+// resume lets workers start the jobs of a queue or job type again. Pending jobs
+// are queued again, since workers acknowledged their messages without running
+// them while the pause was on.
+func resume(application *app.Application, scope repository.PauseScope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name, ok := targetName(c, string(scope))
+		if !ok {
+			return
+		}
+
+		var requeued int64
+		err := application.InTx(c.Request.Context(), func(q *repository.Queries) error {
+			deleted, err := q.DeletePause(c.Request.Context(), repository.DeletePauseParams{Scope: scope, Name: name})
+			if err != nil {
+				return err
+			}
+			if deleted == 0 {
+				return errNotPaused
+			}
+
+			if scope == repository.PauseScopeQueue {
+				requeued, err = q.RequeuePendingQueueJobs(c.Request.Context(), name)
+			} else {
+				requeued, err = q.RequeuePendingJobTypeJobs(c.Request.Context(), name)
+			}
+			return err
+		})
+		if errors.Is(err, errNotPaused) {
+			c.JSON(http.StatusConflict, gin.H{"error": scopeLabel(string(scope)) + " is not paused"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to resume " + scopeLabel(string(scope))})
+			return
+		}
+
+		if err := application.CachePause(c.Request.Context(), scope, name, false); err != nil {
+			application.Logger.Printf("error caching resume of %s %q: %v", scope, name, err)
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": scopeLabel(string(scope)) + " resumed successfully",
+			"data":    gin.H{"scope": scope, "name": name, "requeued_jobs": requeued},
+		})
+	}
+}
+
+// targetName reads the queue, job type or key a pause or rate limit applies to
+// from the URL, writing a validation error if it can't be used. Job types are
+// matched the way executors match them.
+func targetName(c *gin.Context, scope string) (string, bool) {
+	name := c.Param("name")
+	if scope == string(repository.PauseScopeJobType) && strings.TrimSpace(name) != "" {
+		name = executor.NormalizeType(name)
+	}
+
+	if scope == string(repository.PauseScopeQueue) && !queue.ValidQueueName(name) {
+		customerrors.FailedValidationResponse(c, map[string]string{"name": "must be 1 to 64 letters, digits or underscores"})
+		return "", false
+	}
+	if name == "" {
+		customerrors.FailedValidationResponse(c, map[string]string{"name": "must not be empty"})
+		return "", false
+	}
+	return name, true
+}
+
+func scopeLabel(scope string) string {
+	return strings.ReplaceAll(scope, "_", " ")
+}
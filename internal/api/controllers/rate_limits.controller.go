@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tomiwa-a/Relay/internal/api/app"
+	"github.com/tomiwa-a/Relay/internal/api/customerrors"
+	"github.com/tomiwa-a/Relay/internal/repository"
+)
+
+func GetAllRateLimits(application *app.Application) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limits, err := application.Repository.ListRateLimits(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch rate limits"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "rate limits fetched successfully",
+			"data":    limits,
+		})
+	}
+}
+
+// SetRateLimit creates or replaces the rate limit of a queue, job type or rate limit key
+func SetRateLimit(application *app.Application) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, name, ok := rateLimitTarget(c)
+		if !ok {
+			return
+		}
+
+		var req RateLimitRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if req.PeriodSeconds < 0 {
+			customerrors.FailedValidationResponse(c, map[string]string{"period_seconds": "must not be negative"})
+			return
+		}
+		if req.Burst < 0 {
+			customerrors.FailedValidationResponse(c, map[string]string{"burst": "must not be negative"})
+			return
+		}
+
+		params := repository.UpsertRateLimitParams{
+			Scope:         scope,
+			Name:          name,
+			Rate:          req.Rate,
+			PeriodSeconds: max(req.PeriodSeconds, 1),
+			Burst:         req.Burst,
+		}
+		if params.Burst == 0 {
+			params.Burst = req.Rate
+		}
+
+		limit, err := application.Repository.UpsertRateLimit(c.Request.Context(), params)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to set rate limit"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "rate limit set successfully",
+			"data":    limit,
+		})
+	}
+}
+
+func DeleteRateLimit(application *app.Application) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, name, ok := rateLimitTarget(c)
+		if !ok {
+			return
+		}
+
+		deleted, err := application.Repository.DeleteRateLimit(c.Request.Context(), repository.DeleteRateLimitParams{
+			Scope: scope,
+			Name:  name,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete rate limit"})
+			return
+		}
+		if deleted == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "rate limit not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "rate limit deleted successfully",
+		})
+	}
+}
+
+func rateLimitTarget(c *gin.Context) (repository.RateLimitScope, string, bool) {
+	scope := repository.RateLimitScope(c.Param("scope"))
+	switch scope {
+	case repository.RateLimitScopeQueue, repository.RateLimitScopeJobType, repository.RateLimitScopeKey:
+	default:
+		customerrors.FailedValidationResponse(c, map[string]string{"scope": "must be one of queue, job_type or key"})
+		return "", "", false
+	}
+
+	name, ok := targetName(c, string(scope))
+	return scope, name, ok
+}
@@ -0,0 +1,9 @@
+package controllers
+
+// RateLimitRequest lets jobs start at most Rate times every PeriodSeconds,
+// with bursts of up to Burst jobs
+type RateLimitRequest struct {
+	Rate          int32 `json:"rate" binding:"required,min=1"`
+	PeriodSeconds int32 `json:"period_seconds"` // defaults to 1
+	Burst         int32 `json:"burst"`          // defaults to Rate
+}
@@ -0,0 +1,236 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/tomiwa-a/Relay/internal/api/app"
+	"github.com/tomiwa-a/Relay/internal/api/customerrors"
+	"github.com/tomiwa-a/Relay/internal/repository"
+	"github.com/tomiwa-a/Relay/internal/scheduler"
+)
+
+func GetAllSchedules(application *app.Application) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		schedules, err := application.Repository.ListSchedules(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch schedules"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "schedules fetched successfully",
+			"data":    schedules,
+		})
+	}
+}
+
+func GetSingleSchedule(application *app.Application) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scheduleIDInt, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid schedule ID"})
+			return
+		}
+
+		schedule, err := application.Repository.GetSchedule(c.Request.Context(), int32(scheduleIDInt))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "schedule not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "schedule fetched successfully",
+			"data":    schedule,
+		})
+	}
+}
+
+func AddSchedule(application *app.Application) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req ScheduleRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		params, validationErrors := scheduleParams(application, req)
+		if validationErrors != nil {
+			customerrors.FailedValidationResponse(c, validationErrors)
+			return
+		}
+
+		schedule, err := application.Repository.CreateSchedule(c.Request.Context(), params)
+		if isUniqueViolation(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "a schedule with this name already exists"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create schedule"})
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"message": "schedule created successfully",
+			"data":    schedule,
+		})
+	}
+}
+
+func UpdateSchedule(application *app.Application) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scheduleIDInt, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid schedule ID"})
+			return
+		}
+
+		var req ScheduleRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		params, validationErrors := scheduleParams(application, req)
+		if validationErrors != nil {
+			customerrors.FailedValidationResponse(c, validationErrors)
+			return
+		}
+
+		// The next run is recomputed from now, so changing the expression never
+		// fires the ticks it skipped over
+		schedule, err := application.Repository.UpdateSchedule(c.Request.Context(), repository.UpdateScheduleParams{
+			ID:             int32(scheduleIDInt),
+			Name:           params.Name,
+			CronExpression: params.CronExpression,
+			Timezone:       params.Timezone,
+			Title:          params.Title,
+			Description:    params.Description,
+			Payload:        params.Payload,
+			MaxRetries:     params.MaxRetries,
+			TimeoutSeconds: params.TimeoutSeconds,
+			OverlapPolicy:  params.OverlapPolicy,
+			CatchUp:        params.CatchUp,
+			Enabled:        params.Enabled,
+			NextRunAt:      params.NextRunAt,
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "schedule not found"})
+			return
+		}
+		if isUniqueViolation(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "a schedule with this name already exists"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update schedule"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "schedule updated successfully",
+			"data":    schedule,
+		})
+	}
+}
+
+func DeleteSchedule(application *app.Application) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scheduleIDInt, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid schedule ID"})
+			return
+		}
+
+		deleted, err := application.Repository.DeleteSchedule(c.Request.Context(), int32(scheduleIDInt))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete schedule"})
+			return
+		}
+		if deleted == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "schedule not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "schedule deleted successfully",
+		})
+	}
+}
+
+// scheduleParams validates a schedule request and fills in the same job
+// defaults as AddJob
+func scheduleParams(application *app.Application, req ScheduleRequest) (repository.CreateScheduleParams, map[string]string) {
+	if _, err := application.Executors.Resolve(req.Payload); err != nil {
+		return repository.CreateScheduleParams{}, map[string]string{"payload": err.Error()}
+	}
+	if err := checkReferences(req.Payload, false, nil); err != nil {
+		return repository.CreateScheduleParams{}, map[string]string{"payload": err.Error()}
+	}
+
+	timezone := req.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
+
+	nextRunAt, err := scheduler.NextRun(req.CronExpression, timezone, time.Now())
+	if err != nil {
+		return repository.CreateScheduleParams{}, map[string]string{"cron_expression": err.Error()}
+	}
+
+	overlapPolicy := repository.OverlapPolicySkip
+	if req.OverlapPolicy != "" {
+		overlapPolicy = repository.OverlapPolicy(req.OverlapPolicy)
+	}
+	switch overlapPolicy {
+	case repository.OverlapPolicySkip, repository.OverlapPolicyQueue, repository.OverlapPolicyAllow:
+	default:
+		return repository.CreateScheduleParams{}, map[string]string{"overlap_policy": "must be one of skip, queue or allow"}
+	}
+
+	description := pgtype.Text{}
+	if req.Description != "" {
+		description = pgtype.Text{String: req.Description, Valid: true}
+	}
+
+	maxRetries := pgtype.Int4{Int32: 3, Valid: true}
+	if req.MaxRetries > 0 {
+		maxRetries = pgtype.Int4{Int32: req.MaxRetries, Valid: true}
+	}
+
+	timeoutSeconds := pgtype.Int4{Int32: 30, Valid: true} // Default 30 seconds
+	if req.TimeoutSeconds > 0 {
+		timeoutSeconds = pgtype.Int4{Int32: req.TimeoutSeconds, Valid: true}
+	}
+
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+
+	return repository.CreateScheduleParams{
+		Name:           req.Name,
+		CronExpression: req.CronExpression,
+		Timezone:       timezone,
+		Title:          req.Title,
+		Description:    description,
+		Payload:        req.Payload,
+		MaxRetries:     maxRetries,
+		TimeoutSeconds: timeoutSeconds,
+		OverlapPolicy:  overlapPolicy,
+		CatchUp:        req.CatchUp,
+		Enabled:        enabled,
+		NextRunAt:      pgtype.Timestamp{Time: nextRunAt, Valid: true},
+	}, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
@@ -0,0 +1,17 @@
+package controllers
+
+import "encoding/json"
+
+type ScheduleRequest struct {
+	Name           string          `json:"name" binding:"required"`
+	CronExpression string          `json:"cron_expression" binding:"required"`
+	Timezone       string          `json:"timezone"`
+	Title          string          `json:"title" binding:"required"`
+	Description    string          `json:"description"`
+	Payload        json.RawMessage `json:"payload" binding:"required"`
+	MaxRetries     int32           `json:"max_retries"`
+	TimeoutSeconds int32           `json:"timeout_seconds"`
+	OverlapPolicy  string          `json:"overlap_policy"`
+	CatchUp        bool            `json:"catch_up"`
+	Enabled        *bool           `json:"enabled"`
+}
@@ -0,0 +1,212 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/tomiwa-a/Relay/internal/api/app"
+	"github.com/tomiwa-a/Relay/internal/api/customerrors"
+	"github.com/tomiwa-a/Relay/internal/repository"
+)
+
+func GetAllWorkflows(application *app.Application) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		workflows, err := application.Repository.ListWorkflows(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch workflows"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "workflows fetched successfully",
+			"data":    workflows,
+		})
+	}
+}
+
+func GetSingleWorkflow(application *app.Application) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		workflowIDInt, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid workflow ID"})
+			return
+		}
+
+		workflow, err := application.Repository.GetWorkflow(c.Request.Context(), int32(workflowIDInt))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "workflow not found"})
+			return
+		}
+
+		workflowID := pgtype.Int4{Int32: workflow.ID, Valid: true}
+		steps, err := application.Repository.ListWorkflowJobs(c.Request.Context(), workflowID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch workflow steps"})
+			return
+		}
+
+		dependencies, err := application.Repository.ListWorkflowDependencies(c.Request.Context(), workflowID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch workflow dependencies"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "workflow fetched successfully",
+			"data":    WorkflowDetail{Workflow: workflow, Steps: steps, Dependencies: dependencies},
+		})
+	}
+}
+
+func AddWorkflow(application *app.Application) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req CreateWorkflowRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		order, validationErrors := workflowOrder(req.Steps)
+		if validationErrors != nil {
+			customerrors.FailedValidationResponse(c, validationErrors)
+			return
+		}
+
+		params := make(map[string]repository.CreateJobParams, len(req.Steps))
+		for _, step := range req.Steps {
+			key := "steps." + step.Name
+			if step.ParentJobID != nil || step.RunAt != nil || step.DelaySeconds != 0 || step.IdempotencyKey != "" ||
+				step.UniqueKey != "" || step.OnSuccess != nil || step.OnFailure != nil || step.OnComplete != nil {
+				customerrors.FailedValidationResponse(c, map[string]string{key: "cannot set parent_job_id, run_at, delay_seconds, idempotency_key, unique_key or chained jobs"})
+				return
+			}
+
+			stepParams, validationErrors := jobParams(application, step.CreateJobRequest, key+".")
+			if validationErrors != nil {
+				customerrors.FailedValidationResponse(c, validationErrors)
+				return
+			}
+			if err := checkReferences(step.Payload, false, step.DependsOn); err != nil {
+				customerrors.FailedValidationResponse(c, map[string]string{key + ".payload": err.Error()})
+				return
+			}
+			params[step.Name] = stepParams
+		}
+
+		// Steps are created in dependency order, so the jobs a step waits on
+		// already exist when its edges are stored
+		var detail WorkflowDetail
+		err := application.InTx(c.Request.Context(), func(q *repository.Queries) error {
+			workflow, err := q.CreateWorkflow(c.Request.Context(), req.Name)
+			if err != nil {
+				return err
+			}
+			detail.Workflow = workflow
+
+			jobIDs := make(map[string]int32, len(order))
+			for _, step := range order {
+				stepParams := params[step.Name]
+				stepParams.WorkflowID = pgtype.Int4{Int32: workflow.ID, Valid: true}
+				stepParams.StepName = pgtype.Text{String: step.Name, Valid: true}
+				if len(step.DependsOn) > 0 {
+					stepParams.Status = repository.NullJobStatus{JobStatus: repository.JobStatusWaiting, Valid: true}
+				}
+
+				job, err := q.CreateJob(c.Request.Context(), stepParams)
+				if err != nil {
+					return err
+				}
+				jobIDs[step.Name] = job.ID
+				detail.Steps = append(detail.Steps, job)
+
+				for _, parent := range step.DependsOn {
+					dependency := repository.JobDependency{JobID: job.ID, DependsOnJobID: jobIDs[parent]}
+					err := q.CreateJobDependency(c.Request.Context(), repository.CreateJobDependencyParams(dependency))
+					if err != nil {
+						return err
+					}
+					detail.Dependencies = append(detail.Dependencies, dependency)
+				}
+
+				if len(step.DependsOn) == 0 {
+					if _, err := q.CreateOutboxMessage(c.Request.Context(), job.ID); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create workflow"})
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"message": "workflow created successfully",
+			"data":    detail,
+		})
+	}
+}
+
+// workflowOrder checks that step names are unique, dependencies exist and the
+// graph has no cycles, and returns the steps with every step after the steps it
+// depends on
+func workflowOrder(steps []WorkflowStepRequest) ([]WorkflowStepRequest, map[string]string) {
+	byName := make(map[string]WorkflowStepRequest, len(steps))
+	for _, step := range steps {
+		if _, ok := byName[step.Name]; ok {
+			return nil, map[string]string{"steps": fmt.Sprintf("step name %q is used more than once", step.Name)}
+		}
+		byName[step.Name] = step
+	}
+
+	// Kahn's algorithm: repeatedly take the steps whose dependencies are all placed
+	remaining := make(map[string]int, len(steps))
+	dependents := make(map[string][]string, len(steps))
+	for _, step := range steps {
+		for _, parent := range step.DependsOn {
+			if _, ok := byName[parent]; !ok {
+				return nil, map[string]string{"steps." + step.Name + ".depends_on": fmt.Sprintf("unknown step %q", parent)}
+			}
+			if parent == step.Name {
+				return nil, map[string]string{"steps." + step.Name + ".depends_on": "a step cannot depend on itself"}
+			}
+			if slices.Contains(dependents[parent], step.Name) {
+				return nil, map[string]string{"steps." + step.Name + ".depends_on": fmt.Sprintf("step %q is listed more than once", parent)}
+			}
+			dependents[parent] = append(dependents[parent], step.Name)
+			remaining[step.Name]++
+		}
+	}
+
+	order := make([]WorkflowStepRequest, 0, len(steps))
+	for _, step := range steps {
+		if remaining[step.Name] == 0 {
+			order = append(order, step)
+		}
+	}
+	for i := 0; i < len(order); i++ {
+		for _, name := range dependents[order[i].Name] {
+			remaining[name]--
+			if remaining[name] == 0 {
+				order = append(order, byName[name])
+			}
+		}
+	}
+
+	if len(order) < len(steps) {
+		var cyclic []string
+		for _, step := range steps {
+			if remaining[step.Name] > 0 {
+				cyclic = append(cyclic, step.Name)
+			}
+		}
+		return nil, map[string]string{"steps": fmt.Sprintf("dependencies form a cycle through %s", strings.Join(cyclic, ", "))}
+	}
+	return order, nil
+}
@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"strings"
+	"testing"
+)
+
+func step(name string, dependsOn ...string) WorkflowStepRequest {
+	return WorkflowStepRequest{Name: name, DependsOn: dependsOn}
+}
+
+func TestWorkflowOrderRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		steps   []WorkflowStepRequest
+		field   string
+		message string
+	}{
+		{
+			name:    "duplicate step name",
+			steps:   []WorkflowStepRequest{step("extract"), step("extract")},
+			field:   "steps",
+			message: `step name "extract" is used more than once`,
+		},
+		{
+			name:    "unknown dependency",
+			steps:   []WorkflowStepRequest{step("extract"), step("report", "transform")},
+			field:   "steps.report.depends_on",
+			message: `unknown step "transform"`,
+		},
+		{
+			name:    "depends on itself",
+			steps:   []WorkflowStepRequest{step("report", "report")},
+			field:   "steps.report.depends_on",
+			message: "a step cannot depend on itself",
+		},
+		{
+			name:    "dependency listed twice",
+			steps:   []WorkflowStepRequest{step("extract"), step("report", "extract", "extract")},
+			field:   "steps.report.depends_on",
+			message: `step "extract" is listed more than once`,
+		},
+		{
+			name:    "two step cycle",
+			steps:   []WorkflowStepRequest{step("a", "b"), step("b", "a")},
+			field:   "steps",
+			message: "dependencies form a cycle through a, b",
+		},
+		{
+			name:    "cycle behind a valid step",
+			steps:   []WorkflowStepRequest{step("extract"), step("a", "extract", "c"), step("b", "a"), step("c", "b"), step("report", "c")},
+			field:   "steps",
+			message: "dependencies form a cycle through a, b, c, report",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order, validationErrors := workflowOrder(tt.steps)
+			if validationErrors == nil {
+				t.Fatalf("workflowOrder returned order %v, want a validation error", order)
+			}
+			if got := validationErrors[tt.field]; got != tt.message {
+				t.Errorf("validation errors = %v, want %s: %q", validationErrors, tt.field, tt.message)
+			}
+		})
+	}
+}
+
+func TestWorkflowOrder(t *testing.T) {
+	steps := []WorkflowStepRequest{
+		step("report", "extract-orders", "extract-users"),
+		step("extract-orders"),
+		step("notify", "report"),
+		step("extract-users"),
+	}
+
+	order, validationErrors := workflowOrder(steps)
+	if validationErrors != nil {
+		t.Fatalf("workflowOrder returned validation errors: %v", validationErrors)
+	}
+
+	placed := make(map[string]bool, len(order))
+	names := make([]string, 0, len(order))
+	for _, s := range order {
+		for _, parent := range s.DependsOn {
+			if !placed[parent] {
+				t.Errorf("step %q comes before its dependency %q", s.Name, parent)
+			}
+		}
+		placed[s.Name] = true
+		names = append(names, s.Name)
+	}
+	if len(order) != len(steps) {
+		t.Errorf("order = %s, want all %d steps", strings.Join(names, ", "), len(steps))
+	}
+}
@@ -0,0 +1,22 @@
+package controllers
+
+import "github.com/tomiwa-a/Relay/internal/repository"
+
+type CreateWorkflowRequest struct {
+	Name  string                `json:"name" binding:"required"`
+	Steps []WorkflowStepRequest `json:"steps" binding:"required,min=1,dive"`
+}
+
+// WorkflowStepRequest is a job spec with a step name and the steps it waits on
+type WorkflowStepRequest struct {
+	CreateJobRequest
+	Name      string   `json:"name" binding:"required"`
+	DependsOn []string `json:"depends_on"`
+}
+
+// WorkflowDetail is a workflow together with its steps and the edges between them
+type WorkflowDetail struct {
+	repository.Workflow
+	Steps        []repository.Job
+	Dependencies []repository.JobDependency
+}
@@ -11,8 +11,11 @@ func RegisterJobRoutes(r *gin.Engine, app *app.Application) {
 	jobs := r.Group("jobs")
 
 	jobs.GET("", controllers.GetAllJobs(app))
+	jobs.GET("/scheduled", controllers.GetScheduledJobs(app))
 	jobs.GET("/:id", controllers.GetSingleJob(app))
 	jobs.POST("", controllers.AddJob(app))
 	jobs.GET("/:id/logs", controllers.GetJobLogs(app))
+	jobs.GET("/:id/attempts", controllers.GetJobAttempts(app))
 	jobs.POST("/:id/replay", controllers.ReplayJob(app))
+	jobs.POST("/:id/cancel", controllers.CancelJob(app))
 }
@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/tomiwa-a/Relay/internal/api/app"
+	"github.com/tomiwa-a/Relay/internal/api/controllers"
+)
+
+func RegisterPauseRoutes(r *gin.Engine, app *app.Application) {
+
+	r.GET("pauses", controllers.GetAllPauses(app))
+
+	queues := r.Group("queues")
+
+	queues.POST("/:name/pause", controllers.PauseQueue(app))
+	queues.POST("/:name/resume", controllers.ResumeQueue(app))
+
+	jobTypes := r.Group("job-types")
+
+	jobTypes.POST("/:name/pause", controllers.PauseJobType(app))
+	jobTypes.POST("/:name/resume", controllers.ResumeJobType(app))
+}
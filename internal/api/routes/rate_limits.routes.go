@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/tomiwa-a/Relay/internal/api/app"
+	"github.com/tomiwa-a/Relay/internal/api/controllers"
+)
+
+func RegisterRateLimitRoutes(r *gin.Engine, app *app.Application) {
+
+	rateLimits := r.Group("rate-limits")
+
+	rateLimits.GET("", controllers.GetAllRateLimits(app))
+	rateLimits.PUT("/:scope/:name", controllers.SetRateLimit(app))
+	rateLimits.DELETE("/:scope/:name", controllers.DeleteRateLimit(app))
+}
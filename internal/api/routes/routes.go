@@ -12,5 +12,9 @@ func HandleRequests(r *gin.Engine, app *app.Application) {
 	r.NoMethod(customerrors.MethodNotAllowedResponse)
 
 	RegisterJobRoutes(r, app)
+	RegisterScheduleRoutes(r, app)
+	RegisterWorkflowRoutes(r, app)
+	RegisterPauseRoutes(r, app)
+	RegisterRateLimitRoutes(r, app)
 
 }
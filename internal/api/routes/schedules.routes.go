@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/tomiwa-a/Relay/internal/api/app"
+	"github.com/tomiwa-a/Relay/internal/api/controllers"
+)
+
+func RegisterScheduleRoutes(r *gin.Engine, app *app.Application) {
+
+	schedules := r.Group("schedules")
+
+	schedules.GET("", controllers.GetAllSchedules(app))
+	schedules.GET("/:id", controllers.GetSingleSchedule(app))
+	schedules.POST("", controllers.AddSchedule(app))
+	schedules.PUT("/:id", controllers.UpdateSchedule(app))
+	schedules.DELETE("/:id", controllers.DeleteSchedule(app))
+}
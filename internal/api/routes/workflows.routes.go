@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/tomiwa-a/Relay/internal/api/app"
+	"github.com/tomiwa-a/Relay/internal/api/controllers"
+)
+
+func RegisterWorkflowRoutes(r *gin.Engine, app *app.Application) {
+
+	workflows := r.Group("workflows")
+
+	workflows.GET("", controllers.GetAllWorkflows(app))
+	workflows.GET("/:id", controllers.GetSingleWorkflow(app))
+	workflows.POST("", controllers.AddWorkflow(app))
+}
@@ -0,0 +1,307 @@
+package chain
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/tomiwa-a/Relay/internal/repository"
+)
+
+// ErrDependenciesIncomplete means a job can't be replayed because what it waits
+// on hasn't ended the way that releases it
+var ErrDependenciesIncomplete = errors.New("dependencies have not completed")
+
+// ErrUnfilledPayload means the templates in a job's payload can't be filled in
+// from the results it reads
+var ErrUnfilledPayload = errors.New("cannot fill in payload")
+
+// Terminal reports whether a job in this status will not run again on its own
+func Terminal(status repository.JobStatus) bool {
+	switch status {
+	case repository.JobStatusCompleted, repository.JobStatusDead,
+		repository.JobStatusCancelled, repository.JobStatusSkipped:
+		return true
+	}
+	return false
+}
+
+// Settle starts or skips the jobs that wait on a job that reached a terminal
+// status.
+//
+// Chained children: a completed job releases its on_success and on_complete
+// children, a dead one its on_failure and on_complete children.
+// Workflow steps: a step is released once every step it depends on has
+// completed. Jobs that won't run are skipped, along with everything that waits
+// on them.
+//
+// q should be bound to the transaction that stores the job's status, so waiting
+// jobs are released exactly when the outcome is committed.
+func Settle(ctx context.Context, q *repository.Queries, job repository.Job, status repository.JobStatus) error {
+	if !Terminal(status) {
+		return nil
+	}
+
+	if err := settleChildren(ctx, q, job.ID, status, job.Result); err != nil {
+		return err
+	}
+
+	if job.WorkflowID.Valid {
+		return settleWorkflowStep(ctx, q, job.WorkflowID.Int32, job.ID, status)
+	}
+	return nil
+}
+
+// Replay queues a finished job to run again. A chained job or workflow step is
+// replayed only once what it waits on has ended the way that releases it, and
+// its payload templates are filled in as when it is released. If they can't be
+// filled in, ErrUnfilledPayload is returned rather than the job being marked
+// dead, so the transaction q is bound to should be rolled back.
+func Replay(ctx context.Context, q *repository.Queries, job repository.Job) (repository.Job, error) {
+	lookup, err := replayResults(ctx, q, job)
+	if err != nil {
+		return repository.Job{}, err
+	}
+
+	replayed, err := q.ReplayJob(ctx, job.ID)
+	if err != nil {
+		return repository.Job{}, err
+	}
+	if err := enqueue(ctx, q, replayed, "replayed", lookup); err != nil {
+		return repository.Job{}, err
+	}
+
+	if job.WorkflowID.Valid {
+		if err := q.LockWorkflow(ctx, job.WorkflowID.Int32); err != nil {
+			return repository.Job{}, err
+		}
+		if err := q.RefreshWorkflowStatus(ctx, job.WorkflowID.Int32); err != nil {
+			return repository.Job{}, err
+		}
+	}
+	return q.GetJob(ctx, job.ID)
+}
+
+// replayResults returns the results a replayed job's templates read from, or
+// ErrDependenciesIncomplete if it wouldn't have been released yet
+func replayResults(ctx context.Context, q *repository.Queries, job repository.Job) (func(repository.Job) (results, error), error) {
+	switch {
+	case job.ChainTrigger.Valid && job.ParentJobID.Valid:
+		parent, err := q.GetJob(ctx, job.ParentJobID.Int32)
+		if err != nil {
+			return nil, err
+		}
+
+		status := parent.Status.JobStatus
+		released := false
+		switch job.ChainTrigger.ChainTrigger {
+		case repository.ChainTriggerOnSuccess:
+			released = status == repository.JobStatusCompleted
+		case repository.ChainTriggerOnFailure:
+			released = status == repository.JobStatusDead
+		case repository.ChainTriggerOnComplete:
+			released = status == repository.JobStatusCompleted || status == repository.JobStatusDead
+		}
+		if !released {
+			return nil, ErrDependenciesIncomplete
+		}
+
+		return func(repository.Job) (results, error) {
+			return results{parent: parent.Result}, nil
+		}, nil
+
+	case job.WorkflowID.Valid:
+		incomplete, err := q.HasIncompleteDependencies(ctx, job.ID)
+		if err != nil {
+			return nil, err
+		}
+		if incomplete {
+			return nil, ErrDependenciesIncomplete
+		}
+		return dependencyResults(ctx, q), nil
+	}
+
+	return func(repository.Job) (results, error) {
+		return results{}, nil
+	}, nil
+}
+
+func settleChildren(ctx context.Context, q *repository.Queries, jobID int32, status repository.JobStatus, result []byte) error {
+	parentID := pgtype.Int4{Int32: jobID, Valid: true}
+
+	if status == repository.JobStatusCompleted || status == repository.JobStatusDead {
+		released, err := q.ReleaseChildJobs(ctx, repository.ReleaseChildJobsParams{
+			ParentJobID: parentID,
+			Succeeded:   status == repository.JobStatusCompleted,
+		})
+		if err != nil {
+			return err
+		}
+
+		parentResults := func(repository.Job) (results, error) {
+			return results{parent: result}, nil
+		}
+		if err := release(ctx, q, released, fmt.Sprintf("triggered by parent job [%d] ending %s", jobID, status), parentResults); err != nil {
+			return err
+		}
+	}
+
+	skipped, err := q.SkipChildJobs(ctx, parentID)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range skipped {
+		if err := logChild(ctx, q, id, fmt.Sprintf("skipped because parent job [%d] ended %s", jobID, status)); err != nil {
+			return err
+		}
+		if err := settleChildren(ctx, q, id, repository.JobStatusSkipped, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// settleWorkflowStep holds the workflow lock while it checks dependents, so two
+// steps finishing at the same time can't both miss that the other completed
+func settleWorkflowStep(ctx context.Context, q *repository.Queries, workflowID, jobID int32, status repository.JobStatus) error {
+	if err := q.LockWorkflow(ctx, workflowID); err != nil {
+		return err
+	}
+
+	if status == repository.JobStatusCompleted {
+		released, err := q.ReleaseDependentJobs(ctx, jobID)
+		if err != nil {
+			return err
+		}
+
+		if err := release(ctx, q, released, "all dependencies completed", dependencyResults(ctx, q)); err != nil {
+			return err
+		}
+	} else if err := skipDependents(ctx, q, jobID, status); err != nil {
+		return err
+	}
+
+	return q.RefreshWorkflowStatus(ctx, workflowID)
+}
+
+func skipDependents(ctx context.Context, q *repository.Queries, jobID int32, status repository.JobStatus) error {
+	skipped, err := q.SkipDependentJobs(ctx, jobID)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range skipped {
+		if err := logChild(ctx, q, id, fmt.Sprintf("skipped because dependency job [%d] ended %s", jobID, status)); err != nil {
+			return err
+		}
+		if err := skipDependents(ctx, q, id, repository.JobStatusSkipped); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dependencyResults returns the results of the workflow steps a job depends on, by step name
+func dependencyResults(ctx context.Context, q *repository.Queries) func(repository.Job) (results, error) {
+	return func(job repository.Job) (results, error) {
+		rows, err := q.ListDependencyResults(ctx, job.ID)
+		if err != nil {
+			return results{}, err
+		}
+
+		steps := make(map[string][]byte, len(rows))
+		for _, row := range rows {
+			steps[row.StepName.String] = row.Result
+		}
+		return results{steps: steps}, nil
+	}
+}
+
+// release queues jobs that were moved from waiting to pending, filling in the
+// templates in their payloads first. A job whose templates can't be filled in
+// is marked dead instead.
+func release(ctx context.Context, q *repository.Queries, jobIDs []int32, message string, lookup func(repository.Job) (results, error)) error {
+	for _, id := range jobIDs {
+		job, err := q.GetJob(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		err = enqueue(ctx, q, job, message, lookup)
+		if errors.Is(err, ErrUnfilledPayload) {
+			if err := fail(ctx, q, job, fmt.Sprintf("%v, marking as dead", err)); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// enqueue fills in the templates in a pending job's payload and queues it. It
+// returns ErrUnfilledPayload if the templates can't be filled in.
+func enqueue(ctx context.Context, q *repository.Queries, job repository.Job, message string, lookup func(repository.Job) (results, error)) error {
+	inputs, err := lookup(job)
+	if err != nil {
+		return err
+	}
+
+	payload, template, err := inputs.fillJob(job)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnfilledPayload, err)
+	}
+
+	if !bytes.Equal(payload, job.Payload) {
+		err := q.UpdateJobPayload(ctx, repository.UpdateJobPayloadParams{
+			ID:              job.ID,
+			Payload:         payload,
+			PayloadTemplate: template,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := logChild(ctx, q, job.ID, message); err != nil {
+		return err
+	}
+	_, err = q.CreateOutboxMessage(ctx, job.ID)
+	return err
+}
+
+// fail marks a released job dead before it runs and settles the jobs waiting on it
+func fail(ctx context.Context, q *repository.Queries, job repository.Job, message string) error {
+	_, err := q.CreateJobLog(ctx, repository.CreateJobLogParams{
+		JobID:   job.ID,
+		Level:   repository.LogLevelERROR,
+		Message: message,
+	})
+	if err != nil {
+		return err
+	}
+
+	job, err = q.UpdateJobStatus(ctx, repository.UpdateJobStatusParams{
+		ID:      job.ID,
+		Status:  repository.NullJobStatus{JobStatus: repository.JobStatusDead, Valid: true},
+		Retries: job.Retries,
+	})
+	if err != nil {
+		return err
+	}
+	return Settle(ctx, q, job, repository.JobStatusDead)
+}
+
+func logChild(ctx context.Context, q *repository.Queries, jobID int32, message string) error {
+	_, err := q.CreateJobLog(ctx, repository.CreateJobLogParams{
+		JobID:   jobID,
+		Level:   repository.LogLevelINFO,
+		Message: message,
+	})
+	return err
+}
@@ -0,0 +1,191 @@
+package chain
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/tomiwa-a/Relay/internal/repository"
+)
+
+var (
+	// Only expressions starting with parent. or steps. are templates. Other
+	// {{ ... }} in a payload, like a Go template passed to a command, are left alone.
+	templatePattern = regexp.MustCompile(`\{\{(\s*(?:parent|steps)\..*?)\}\}`)
+	namePattern     = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+)
+
+// Reference is a {{ ... }} template in a job payload. It points into the result
+// of the job's parent ({{ parent.result.path }}) or of a workflow step the job
+// depends on ({{ steps.extract.result.path }}).
+type Reference struct {
+	Step string   // empty for the parent
+	Path []string // object keys or array indexes inside the result
+}
+
+func parseReference(expression string) (Reference, error) {
+	expression = strings.TrimSpace(expression)
+	parts := strings.Split(expression, ".")
+	for _, part := range parts {
+		if !namePattern.MatchString(part) {
+			return Reference{}, fmt.Errorf("invalid template {{ %s }}", expression)
+		}
+	}
+
+	switch {
+	case len(parts) >= 2 && parts[0] == "parent" && parts[1] == "result":
+		return Reference{Path: parts[2:]}, nil
+	case len(parts) >= 3 && parts[0] == "steps" && parts[2] == "result":
+		return Reference{Step: parts[1], Path: parts[3:]}, nil
+	}
+	return Reference{}, fmt.Errorf("invalid template {{ %s }}, must start with parent.result or steps.<name>.result", expression)
+}
+
+// References lists the templates used in the string values of a payload
+func References(payload json.RawMessage) ([]Reference, error) {
+	var refs []Reference
+	_, err := render(payload, func(ref Reference) (string, error) {
+		refs = append(refs, ref)
+		return "", nil
+	})
+	return refs, err
+}
+
+// results holds the outputs a waiting job can read once it is released
+type results struct {
+	parent []byte
+	steps  map[string][]byte
+}
+
+// fill replaces the templates in a payload. Strings in a result are inserted
+// as-is and other values as JSON. A payload without templates is returned unchanged.
+func (r results) fill(payload json.RawMessage) (json.RawMessage, error) {
+	return render(payload, r.lookup)
+}
+
+// fillJob fills in the templates of a released job. They are read from the
+// payload template, which keeps the payload as submitted once it has been
+// filled in, so a job released again by a replay reads the latest results.
+func (r results) fillJob(job repository.Job) (payload, template json.RawMessage, err error) {
+	template = job.PayloadTemplate
+	if template == nil {
+		template = job.Payload
+	}
+
+	payload, err = r.fill(template)
+	return payload, template, err
+}
+
+func (r results) lookup(ref Reference) (string, error) {
+	name, raw := "parent.result", r.parent
+	if ref.Step != "" {
+		var ok bool
+		name = "steps." + ref.Step + ".result"
+		if raw, ok = r.steps[ref.Step]; !ok {
+			return "", fmt.Errorf("%s: %q is not a dependency", name, ref.Step)
+		}
+	}
+	if len(raw) == 0 {
+		return "", fmt.Errorf("%s is empty", name)
+	}
+
+	value, err := decode(raw)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+
+	for _, key := range ref.Path {
+		var ok bool
+		switch v := value.(type) {
+		case map[string]any:
+			value, ok = v[key]
+		case []any:
+			i, err := strconv.Atoi(key)
+			if ok = err == nil && i >= 0 && i < len(v); ok {
+				value = v[i]
+			}
+		}
+		if !ok {
+			return "", fmt.Errorf("%s has no %q", name, key)
+		}
+		name += "." + key
+	}
+
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	text, err := encode(value)
+	return string(text), err
+}
+
+// render replaces each template in the payload's string values with the text
+// lookup returns for it
+func render(payload json.RawMessage, lookup func(Reference) (string, error)) (json.RawMessage, error) {
+	if !templatePattern.Match(payload) {
+		return payload, nil
+	}
+
+	value, err := decode(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var renderErr error
+	var walk func(any) any
+	walk = func(value any) any {
+		switch v := value.(type) {
+		case map[string]any:
+			for key, item := range v {
+				v[key] = walk(item)
+			}
+		case []any:
+			for i, item := range v {
+				v[i] = walk(item)
+			}
+		case string:
+			return templatePattern.ReplaceAllStringFunc(v, func(match string) string {
+				ref, err := parseReference(templatePattern.FindStringSubmatch(match)[1])
+				if err == nil {
+					match, err = lookup(ref)
+				}
+				if err != nil && renderErr == nil {
+					renderErr = err
+				}
+				return match
+			})
+		}
+		return value
+	}
+
+	value = walk(value)
+	if renderErr != nil {
+		return nil, renderErr
+	}
+	return encode(value)
+}
+
+// decode keeps numbers as written so large IDs survive the round trip
+func decode(data []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// encode leaves characters like & alone, since payloads hold shell commands and URLs rather than HTML
+func encode(value any) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
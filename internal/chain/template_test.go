@@ -0,0 +1,130 @@
+package chain
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/tomiwa-a/Relay/internal/repository"
+)
+
+func TestReferencesIgnoresOtherBraces(t *testing.T) {
+	payloads := []string{
+		`{"type":"SHELL","command":"docker","args":["inspect","-f","{{.State.Running}}","web"]}`,
+		`{"type":"HTTP","url":"http://mail.internal/send","body":"Hello {{name}}"}`,
+		`{"type":"SHELL","command":"echo","args":["{{ parentheses }}","{{ stepsize.x }}"]}`,
+	}
+
+	for _, payload := range payloads {
+		refs, err := References(json.RawMessage(payload))
+		if err != nil {
+			t.Errorf("References(%s) returned error: %v", payload, err)
+		}
+		if len(refs) != 0 {
+			t.Errorf("References(%s) = %v, want none", payload, refs)
+		}
+	}
+}
+
+func TestReferences(t *testing.T) {
+	payload := `{"args":["{{ parent.result.id }}","{{steps.extract.result.rows.0}}","{{.State.Running}}"]}`
+
+	refs, err := References(json.RawMessage(payload))
+	if err != nil {
+		t.Fatalf("References returned error: %v", err)
+	}
+	if len(refs) != 2 {
+		t.Fatalf("got %d references, want 2: %v", len(refs), refs)
+	}
+	if refs[0].Step != "" || len(refs[0].Path) != 1 || refs[0].Path[0] != "id" {
+		t.Errorf("first reference = %+v, want parent.result.id", refs[0])
+	}
+	if refs[1].Step != "extract" || len(refs[1].Path) != 2 {
+		t.Errorf("second reference = %+v, want steps.extract.result.rows.0", refs[1])
+	}
+}
+
+func TestReferencesRejectsInvalid(t *testing.T) {
+	for _, payload := range []string{
+		`{"body":"{{ parent.output }}"}`,
+		`{"body":"{{ steps.extract }}"}`,
+		`{"body":"{{ parent.result.a b }}"}`,
+	} {
+		if _, err := References(json.RawMessage(payload)); err == nil {
+			t.Errorf("References(%s) returned no error", payload)
+		}
+	}
+}
+
+func TestFill(t *testing.T) {
+	r := results{
+		parent: []byte(`{"id":12345678901234567890,"name":"web","tags":["a","b"]}`),
+		steps:  map[string][]byte{"extract": []byte(`{"rows":[{"n":1}]}`)},
+	}
+	payload := `{"args":["-f","{{.State.Running}}","{{ parent.result.name }}"],` +
+		`"id":"{{parent.result.id}}","tags":"{{ parent.result.tags }}",` +
+		`"row":"{{ steps.extract.result.rows.0 }}","body":"Hello {{name}} & co"}`
+
+	filled, err := r.fill(json.RawMessage(payload))
+	if err != nil {
+		t.Fatalf("fill returned error: %v", err)
+	}
+
+	want := `{"args":["-f","{{.State.Running}}","web"],"body":"Hello {{name}} & co",` +
+		`"id":"12345678901234567890","row":"{\"n\":1}","tags":"[\"a\",\"b\"]"}`
+	if string(filled) != want {
+		t.Errorf("fill =\n%s\nwant\n%s", filled, want)
+	}
+}
+
+func TestFillLeavesPayloadWithoutReferences(t *testing.T) {
+	payload := json.RawMessage(`{"command":"docker", "args":["inspect","-f","{{.State.Running}}"]}`)
+
+	filled, err := results{}.fill(payload)
+	if err != nil {
+		t.Fatalf("fill returned error: %v", err)
+	}
+	if string(filled) != string(payload) {
+		t.Errorf("fill = %s, want payload unchanged", filled)
+	}
+}
+
+func TestFillMissingResult(t *testing.T) {
+	r := results{parent: []byte(`{"id":1}`)}
+	for _, payload := range []string{
+		`{"a":"{{ parent.result.missing }}"}`,
+		`{"a":"{{ steps.other.result }}"}`,
+	} {
+		if _, err := r.fill(json.RawMessage(payload)); err == nil {
+			t.Errorf("fill(%s) returned no error", payload)
+		}
+	}
+}
+
+func TestFillJobAfterParentReplay(t *testing.T) {
+	job := repository.Job{Payload: []byte(`{"command":"cat","args":["{{ parent.result.path }}"]}`)}
+
+	payload, template, err := results{parent: []byte(`{"path":"/tmp/v1"}`)}.fillJob(job)
+	if err != nil {
+		t.Fatalf("fillJob returned error: %v", err)
+	}
+	if want := `{"args":["/tmp/v1"],"command":"cat"}`; string(payload) != want {
+		t.Fatalf("first fill = %s, want %s", payload, want)
+	}
+	if string(template) != string(job.Payload) {
+		t.Fatalf("template = %s, want the submitted payload", template)
+	}
+
+	// What release stores, before the parent is replayed and produces a new result
+	job.Payload, job.PayloadTemplate = payload, template
+
+	payload, template, err = results{parent: []byte(`{"path":"/tmp/v2"}`)}.fillJob(job)
+	if err != nil {
+		t.Fatalf("fillJob returned error: %v", err)
+	}
+	if want := `{"args":["/tmp/v2"],"command":"cat"}`; string(payload) != want {
+		t.Errorf("fill after replay = %s, want %s", payload, want)
+	}
+	if string(template) != string(job.PayloadTemplate) {
+		t.Errorf("template = %s, want it unchanged", template)
+	}
+}
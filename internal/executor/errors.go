@@ -0,0 +1,70 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+)
+
+// PermanentError marks a failure that will not succeed if the job is retried
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// Permanent wraps err so the worker marks the job as dead instead of retrying it
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &PermanentError{Err: err}
+}
+
+// IsPermanent reports whether err, or any error it wraps, is a PermanentError
+func IsPermanent(err error) bool {
+	var permanentErr *PermanentError
+	return errors.As(err, &permanentErr)
+}
+
+// TransientError marks a failure caused by the environment rather than the job,
+// such as a timeout or the process being killed, that may succeed on a retry
+type TransientError struct {
+	Err error
+}
+
+func (e *TransientError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *TransientError) Unwrap() error {
+	return e.Err
+}
+
+// Transient wraps err to record that retrying the job may help
+func Transient(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &TransientError{Err: err}
+}
+
+// IsTransient reports whether err, or any error it wraps, is a TransientError
+func IsTransient(err error) bool {
+	var transientErr *TransientError
+	return errors.As(err, &transientErr)
+}
+
+// ExitError reports a command that ran to completion with a non-zero exit code
+type ExitError struct {
+	Code int32
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("command exited with code %d", e.Code)
+}
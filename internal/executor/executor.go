@@ -3,14 +3,44 @@ package executor
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"slices"
 )
 
+// TypeShell is the payload type handled by ShellExecutor
+const TypeShell = "SHELL"
+
+// BuiltinTypes lists the payload types every worker can run. Jobs of any other
+// type are run by workers embedded in another program.
+var BuiltinTypes = []string{TypeShell, TypeHTTP}
+
+// Builtin reports whether jobType is run by a built-in executor
+func Builtin(jobType string) bool {
+	return slices.Contains(BuiltinTypes, NormalizeType(jobType))
+}
+
 // Executor defines the interface for executing jobs
 type Executor interface {
 	Execute(ctx context.Context, payload json.RawMessage) (*ExecutionResult, error)
 }
 
-// NewExecutor returns the appropriate executor based on payload type
-func NewExecutor() Executor {
-	return &ShellExecutor{}
+// NewDefaultRegistry returns a registry with the built-in executors registered
+func NewDefaultRegistry() *Registry {
+	registry := NewRegistry()
+	registry.Register(TypeShell, &ShellExecutor{})
+	registry.Register(TypeHTTP, &HTTPExecutor{})
+	return registry
+}
+
+// RemoteExecutor stands in for job types that are accepted by the API but
+// handled by workers embedded in another program. Workers hand such jobs on
+// without running them; the error is only a fallback, and can be retried.
+type RemoteExecutor struct{}
+
+func (re *RemoteExecutor) Execute(ctx context.Context, payload json.RawMessage) (*ExecutionResult, error) {
+	err := errors.New("job type is handled by an embedded worker, not this process")
+	return &ExecutionResult{
+		ExitCode: 1,
+		Error:    err,
+	}, err
 }
@@ -0,0 +1,141 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// TypeHTTP is the payload type handled by HTTPExecutor
+const TypeHTTP = "HTTP"
+
+// maxResponseBody caps how much of a response body is kept in the job logs
+const maxResponseBody = 1 << 20
+
+// HTTPExecutor performs HTTP requests
+type HTTPExecutor struct {
+	Client *http.Client
+}
+
+// Execute sends the request described by the payload and captures the response status, headers and body
+func (he *HTTPExecutor) Execute(ctx context.Context, payload json.RawMessage) (*ExecutionResult, error) {
+	var httpPayload HTTPPayload
+	if err := json.Unmarshal(payload, &httpPayload); err != nil {
+		err = Permanent(fmt.Errorf("invalid payload: %v", err))
+		return &ExecutionResult{
+			ExitCode: 1,
+			Error:    err,
+		}, err
+	}
+
+	if httpPayload.URL == "" {
+		err := Permanent(errors.New("invalid payload: url is required"))
+		return &ExecutionResult{
+			ExitCode: 1,
+			Error:    err,
+		}, err
+	}
+
+	// Parse timeout if provided, default to context timeout
+	timeout := 30 * time.Second
+	if httpPayload.Timeout != "" {
+		parsedTimeout, err := time.ParseDuration(httpPayload.Timeout)
+		if err != nil {
+			err = Permanent(fmt.Errorf("invalid timeout format: %v", err))
+			return &ExecutionResult{
+				ExitCode: 1,
+				Error:    err,
+			}, err
+		}
+		timeout = parsedTimeout
+	}
+
+	execCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	method := strings.ToUpper(httpPayload.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	req, err := http.NewRequestWithContext(execCtx, method, httpPayload.URL, requestBody(httpPayload.Body))
+	if err != nil {
+		err = Permanent(fmt.Errorf("invalid request: %v", err))
+		return &ExecutionResult{
+			ExitCode: 1,
+			Error:    err,
+		}, err
+	}
+
+	for key, value := range httpPayload.Headers {
+		req.Header.Set(key, value)
+	}
+
+	client := he.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		if execCtx.Err() == context.DeadlineExceeded {
+			err = fmt.Errorf("request timed out after %v", timeout)
+		}
+		err = Transient(err)
+		return &ExecutionResult{
+			ExitCode: 1,
+			Error:    err,
+		}, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	if err != nil {
+		return &ExecutionResult{
+			ExitCode: 1,
+			Error:    fmt.Errorf("failed to read response body: %v", err),
+		}, err
+	}
+
+	result := &ExecutionResult{
+		Stdout:     string(body),
+		StatusCode: int32(resp.StatusCode),
+		Headers:    resp.Header,
+	}
+
+	if !expectedStatus(httpPayload.ExpectedStatus, resp.StatusCode) {
+		result.ExitCode = 1
+		result.Error = fmt.Errorf("unexpected response status: %d", resp.StatusCode)
+	} else {
+		result.Result = responseResult(resp.Header, body)
+	}
+
+	return result, nil
+}
+
+// requestBody sends JSON strings as plain text and any other JSON value as-is
+func requestBody(raw json.RawMessage) io.Reader {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return strings.NewReader(text)
+	}
+	return bytes.NewReader(raw)
+}
+
+func expectedStatus(expected []int, status int) bool {
+	if len(expected) == 0 {
+		return status >= 200 && status < 300
+	}
+	return slices.Contains(expected, status)
+}
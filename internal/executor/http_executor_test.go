@@ -0,0 +1,232 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func httpPayload(t *testing.T, p HTTPPayload) json.RawMessage {
+	t.Helper()
+	p.Type = TypeHTTP
+	raw, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("marshal payload: %v", err)
+	}
+	return raw
+}
+
+func TestHTTPExecutorSuccess(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Request-Id", "abc")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id":42}`)
+	}))
+	defer server.Close()
+
+	result, err := (&HTTPExecutor{}).Execute(context.Background(), httpPayload(t, HTTPPayload{URL: server.URL}))
+	if err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+	if result.ExitCode != 0 || result.Error != nil {
+		t.Errorf("got exit code %d and error %v, want success", result.ExitCode, result.Error)
+	}
+	if result.StatusCode != http.StatusCreated {
+		t.Errorf("StatusCode = %d, want %d", result.StatusCode, http.StatusCreated)
+	}
+	if got := http.Header(result.Headers).Get("X-Request-Id"); got != "abc" {
+		t.Errorf("X-Request-Id header = %q, want %q", got, "abc")
+	}
+	if result.Stdout != `{"id":42}` {
+		t.Errorf("Stdout = %q, want the response body", result.Stdout)
+	}
+	if string(result.Result) != `{"id":42}` {
+		t.Errorf("Result = %s, want the JSON response body", result.Result)
+	}
+}
+
+func TestHTTPExecutorSendsRequest(t *testing.T) {
+	type request struct {
+		method      string
+		contentType string
+		auth        string
+		body        string
+	}
+	received := make(chan request, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- request{
+			method:      r.Method,
+			contentType: r.Header.Get("Content-Type"),
+			auth:        r.Header.Get("Authorization"),
+			body:        string(body),
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		body json.RawMessage
+		want string
+	}{
+		{name: "json object", body: json.RawMessage(`{"invoice_id":7}`), want: `{"invoice_id":7}`},
+		{name: "string", body: json.RawMessage(`"plain text"`), want: "plain text"},
+		{name: "none", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := httpPayload(t, HTTPPayload{
+				Method:  "post",
+				URL:     server.URL,
+				Headers: map[string]string{"Content-Type": "application/json", "Authorization": "Bearer token"},
+				Body:    tt.body,
+			})
+
+			if _, err := (&HTTPExecutor{}).Execute(context.Background(), payload); err != nil {
+				t.Fatalf("Execute returned error: %v", err)
+			}
+
+			got := <-received
+			if got.method != http.MethodPost {
+				t.Errorf("method = %s, want POST", got.method)
+			}
+			if got.contentType != "application/json" || got.auth != "Bearer token" {
+				t.Errorf("headers = %q, %q, want the payload headers", got.contentType, got.auth)
+			}
+			if got.body != tt.want {
+				t.Errorf("body = %q, want %q", got.body, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPExecutorStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		expected []int
+		wantFail bool
+	}{
+		{name: "2xx by default", status: http.StatusNoContent},
+		{name: "4xx by default", status: http.StatusNotFound, wantFail: true},
+		{name: "5xx by default", status: http.StatusServiceUnavailable, wantFail: true},
+		{name: "expected non-2xx", status: http.StatusConflict, expected: []int{200, 409}},
+		{name: "unexpected 2xx", status: http.StatusAccepted, expected: []int{200}, wantFail: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, `{"error":"nope"}`)
+			}))
+			defer server.Close()
+
+			payload := httpPayload(t, HTTPPayload{URL: server.URL, ExpectedStatus: tt.expected})
+			result, err := (&HTTPExecutor{}).Execute(context.Background(), payload)
+			if err != nil {
+				t.Fatalf("Execute returned error: %v", err)
+			}
+			if result.StatusCode != int32(tt.status) {
+				t.Errorf("StatusCode = %d, want %d", result.StatusCode, tt.status)
+			}
+
+			failed := result.ExitCode != 0 || result.Error != nil
+			if failed != tt.wantFail {
+				t.Errorf("failed = %v (exit code %d, error %v), want %v", failed, result.ExitCode, result.Error, tt.wantFail)
+			}
+			if tt.wantFail {
+				if !strings.Contains(result.Error.Error(), fmt.Sprint(tt.status)) {
+					t.Errorf("error %q doesn't mention the status", result.Error)
+				}
+				if result.Result != nil {
+					t.Errorf("Result = %s, want none for a failed request", result.Result)
+				}
+				if result.Stdout != `{"error":"nope"}` {
+					t.Errorf("Stdout = %q, want the response body", result.Stdout)
+				}
+			}
+		})
+	}
+}
+
+func TestHTTPExecutorTimeout(t *testing.T) {
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-done:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(done)
+
+	start := time.Now()
+	result, err := (&HTTPExecutor{}).Execute(context.Background(), httpPayload(t, HTTPPayload{URL: server.URL, Timeout: "50ms"}))
+	if err == nil {
+		t.Fatal("Execute returned no error for a request that timed out")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Execute took %v, want it to stop at the timeout", elapsed)
+	}
+	if !IsTransient(err) {
+		t.Errorf("error %v is not transient, want timeouts to be retried", err)
+	}
+	if !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("error = %q, want it to say the request timed out", err)
+	}
+	if result.ExitCode != 1 {
+		t.Errorf("ExitCode = %d, want 1", result.ExitCode)
+	}
+}
+
+func TestHTTPExecutorConnectionError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	_, err := (&HTTPExecutor{}).Execute(context.Background(), httpPayload(t, HTTPPayload{URL: url}))
+	if err == nil {
+		t.Fatal("Execute returned no error for a closed server")
+	}
+	if !IsTransient(err) {
+		t.Errorf("error %v is not transient, want connection errors to be retried", err)
+	}
+}
+
+func TestHTTPExecutorInvalidPayload(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload json.RawMessage
+	}{
+		{name: "not json", payload: json.RawMessage(`{`)},
+		{name: "missing url", payload: json.RawMessage(`{"type":"HTTP"}`)},
+		{name: "bad timeout", payload: json.RawMessage(`{"type":"HTTP","url":"http://example.com","timeout":"soon"}`)},
+		{name: "bad method", payload: json.RawMessage(`{"type":"HTTP","url":"http://example.com","method":"GET POST"}`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := (&HTTPExecutor{}).Execute(context.Background(), tt.payload)
+			if err == nil {
+				t.Fatal("Execute returned no error")
+			}
+			if !IsPermanent(err) {
+				t.Errorf("error %v is not permanent, want invalid payloads to fail without retries", err)
+			}
+			if result.ExitCode != 1 {
+				t.Errorf("ExitCode = %d, want 1", result.ExitCode)
+			}
+		})
+	}
+}
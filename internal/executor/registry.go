@@ -0,0 +1,79 @@
+package executor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ErrUnknownType is returned when no executor is registered for a payload type
+var ErrUnknownType = errors.New("unknown executor type")
+
+// Registry maps payload types to the executors that run them
+type Registry struct {
+	mu        sync.RWMutex
+	executors map[string]Executor
+}
+
+// NewRegistry returns an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		executors: make(map[string]Executor),
+	}
+}
+
+// Register adds an executor under the given type name, replacing any existing one.
+// Type names are matched case-insensitively.
+func (r *Registry) Register(jobType string, e Executor) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.executors[NormalizeType(jobType)] = e
+}
+
+// Get returns the executor registered under the given type name
+func (r *Registry) Get(jobType string) (Executor, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	e, ok := r.executors[NormalizeType(jobType)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownType, jobType)
+	}
+	return e, nil
+}
+
+// Resolve reads the "type" field of a job payload and returns the matching executor
+func (r *Registry) Resolve(payload json.RawMessage) (Executor, error) {
+	jobType, err := ParseType(payload)
+	if err != nil {
+		return nil, err
+	}
+	return r.Get(jobType)
+}
+
+// ParseType extracts the "type" field from a job payload, normalized.
+// Payloads without a type are treated as SHELL for backwards compatibility.
+func ParseType(payload json.RawMessage) (string, error) {
+	var envelope struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(payload, &envelope); err != nil {
+		return "", fmt.Errorf("invalid payload: %v", err)
+	}
+
+	return NormalizeType(envelope.Type), nil
+}
+
+// NormalizeType returns the form job types are matched in, so "shell" and "SHELL"
+// are the same type and an empty type is SHELL. The queries that read a job's
+// type from its payload follow the same rule.
+func NormalizeType(jobType string) string {
+	jobType = strings.ToUpper(strings.TrimSpace(jobType))
+	if jobType == "" {
+		return TypeShell
+	}
+	return jobType
+}
@@ -0,0 +1,38 @@
+package executor
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseTypeNormalizes(t *testing.T) {
+	tests := []struct {
+		payload string
+		want    string
+	}{
+		{payload: `{"command":"echo"}`, want: TypeShell},
+		{payload: `{"type":""}`, want: TypeShell},
+		{payload: `{"type":"  "}`, want: TypeShell},
+		{payload: `{"type":" http "}`, want: TypeHTTP},
+		{payload: `{"type":"send-invoice"}`, want: "SEND-INVOICE"},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseType(json.RawMessage(tt.payload))
+		if err != nil {
+			t.Fatalf("ParseType(%s) returned error: %v", tt.payload, err)
+		}
+		if got != tt.want {
+			t.Errorf("ParseType(%s) = %q, want %q", tt.payload, got, tt.want)
+		}
+		if Builtin(got) != (tt.want == TypeShell || tt.want == TypeHTTP) {
+			t.Errorf("Builtin(%q) = %v", got, Builtin(got))
+		}
+	}
+}
+
+func TestBuiltinEmptyType(t *testing.T) {
+	if !Builtin("") {
+		t.Error(`Builtin("") = false, want an empty type to be SHELL`)
+	}
+}
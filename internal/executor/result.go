@@ -0,0 +1,58 @@
+package executor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// ResultFileEnv names the environment variable that tells shell jobs where to
+// write their result
+const ResultFileEnv = "RELAY_RESULT_FILE"
+
+// readResultFile returns the JSON a command wrote to its result file, or nil
+// if it wrote nothing
+func readResultFile(path string) (json.RawMessage, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read result file: %v", err)
+	}
+
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, nil
+	}
+	if !json.Valid(data) {
+		return nil, fmt.Errorf("result file is not valid JSON")
+	}
+	return data, nil
+}
+
+// stdoutResult returns the last line of stdout when it is a JSON object
+func stdoutResult(stdout string) json.RawMessage {
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	last := strings.TrimSpace(lines[len(lines)-1])
+	if !strings.HasPrefix(last, "{") || !json.Valid([]byte(last)) {
+		return nil
+	}
+	return json.RawMessage(last)
+}
+
+// responseResult returns the body of a JSON response
+func responseResult(headers http.Header, body []byte) json.RawMessage {
+	mediaType, _, err := mime.ParseMediaType(headers.Get("Content-Type"))
+	if err != nil {
+		return nil
+	}
+	if mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json") {
+		return nil
+	}
+	if !json.Valid(body) {
+		return nil
+	}
+	return body
+}
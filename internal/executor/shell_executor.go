@@ -4,8 +4,12 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
+	"os"
 	"os/exec"
+	"slices"
 	"syscall"
 	"time"
 )
@@ -17,9 +21,10 @@ type ShellExecutor struct{}
 func (se *ShellExecutor) Execute(ctx context.Context, payload json.RawMessage) (*ExecutionResult, error) {
 	var execPayload ExecutionPayload
 	if err := json.Unmarshal(payload, &execPayload); err != nil {
+		err = Permanent(fmt.Errorf("invalid payload: %v", err))
 		return &ExecutionResult{
 			ExitCode: 1,
-			Error:    fmt.Errorf("invalid payload: %v", err),
+			Error:    err,
 		}, err
 	}
 
@@ -28,9 +33,10 @@ func (se *ShellExecutor) Execute(ctx context.Context, payload json.RawMessage) (
 	if execPayload.Timeout != "" {
 		parsedTimeout, err := time.ParseDuration(execPayload.Timeout)
 		if err != nil {
+			err = Permanent(fmt.Errorf("invalid timeout format: %v", err))
 			return &ExecutionResult{
 				ExitCode: 1,
-				Error:    fmt.Errorf("invalid timeout format: %v", err),
+				Error:    err,
 			}, err
 		}
 		timeout = parsedTimeout
@@ -40,8 +46,20 @@ func (se *ShellExecutor) Execute(ctx context.Context, payload json.RawMessage) (
 	execCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
+	// The command can write its result as JSON to this file
+	resultFile, err := os.CreateTemp("", "relay-result-*.json")
+	if err != nil {
+		return &ExecutionResult{
+			ExitCode: 1,
+			Error:    fmt.Errorf("failed to create result file: %v", err),
+		}, err
+	}
+	resultFile.Close()
+	defer os.Remove(resultFile.Name())
+
 	// Create the command
 	cmd := exec.CommandContext(execCtx, execPayload.Command, execPayload.Args...)
+	cmd.Env = append(os.Environ(), ResultFileEnv+"="+resultFile.Name())
 
 	// Capture stdout and stderr
 	var stdout bytes.Buffer
@@ -50,7 +68,7 @@ func (se *ShellExecutor) Execute(ctx context.Context, payload json.RawMessage) (
 	cmd.Stderr = &stderr
 
 	// Run the command
-	err := cmd.Run()
+	err = cmd.Run()
 
 	result := &ExecutionResult{
 		Stdout: stdout.String(),
@@ -60,22 +78,56 @@ func (se *ShellExecutor) Execute(ctx context.Context, payload json.RawMessage) (
 	// Extract exit code
 	if err != nil {
 		if exitErr, ok := err.(*exec.ExitError); ok {
-			if status, ok := exitErr.Sys().(syscall.WaitStatus); ok {
+			if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+				if execCtx.Err() == context.DeadlineExceeded {
+					result.ExitCode = 124 // Standard timeout exit code
+					result.Error = Transient(fmt.Errorf("command timed out after %v", timeout))
+					return result, result.Error
+				}
+				result.ExitCode = 128 + int32(status.Signal())
+				result.Error = Transient(fmt.Errorf("command killed by signal: %v", status.Signal()))
+			} else if ok {
 				result.ExitCode = int32(status.ExitStatus())
+				result.Error = execPayload.exitError(result.ExitCode)
 			} else {
 				result.ExitCode = 1
+				result.Error = execPayload.exitError(result.ExitCode)
 			}
 		} else if execCtx.Err() == context.DeadlineExceeded {
 			result.ExitCode = 124 // Standard timeout exit code
-			result.Error = fmt.Errorf("command timed out after %v", timeout)
+			result.Error = Transient(fmt.Errorf("command timed out after %v", timeout))
 			return result, result.Error
+		} else if errors.Is(err, exec.ErrNotFound) || errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrPermission) {
+			// The command can't be started, and won't be on a retry either
+			result.ExitCode = 127
+			result.Error = Permanent(err)
 		} else {
 			result.ExitCode = 1
 			result.Error = err
 		}
 	} else {
 		result.ExitCode = 0
+
+		// A result file takes precedence over a JSON object on the last line of stdout
+		result.Result, err = readResultFile(resultFile.Name())
+		if err != nil {
+			result.Error = Permanent(err)
+		} else if result.Result == nil {
+			result.Result = stdoutResult(result.Stdout)
+		}
 	}
 
 	return result, nil
 }
+
+// exitError classifies a non-zero exit code using the payload's exit code rules
+func (p ExecutionPayload) exitError(code int32) error {
+	err := &ExitError{Code: code}
+	if slices.Contains(p.NoRetryOnExitCodes, code) {
+		return Permanent(err)
+	}
+	if len(p.RetryOnExitCodes) > 0 && !slices.Contains(p.RetryOnExitCodes, code) {
+		return Permanent(err)
+	}
+	return err
+}
@@ -1,11 +1,29 @@
 package executor
 
+import "encoding/json"
+
 // ExecutionPayload represents the payload structure for executing a job
 type ExecutionPayload struct {
 	Type    string   `json:"type"`    // e.g., "SHELL"
 	Command string   `json:"command"` // e.g., "/usr/local/bin/script.sh"
 	Args    []string `json:"args"`    // Command arguments
 	Timeout string   `json:"timeout"` // e.g., "5m", "30s"
+
+	// Exit code rules. A code in NoRetryOnExitCodes fails the job permanently;
+	// when RetryOnExitCodes is set, only the codes it lists are retried.
+	RetryOnExitCodes   []int32 `json:"retry_on_exit_codes"`
+	NoRetryOnExitCodes []int32 `json:"no_retry_on_exit_codes"`
+}
+
+// HTTPPayload represents the payload structure for an HTTP request job
+type HTTPPayload struct {
+	Type           string            `json:"type"`            // "HTTP"
+	Method         string            `json:"method"`          // e.g., "POST", defaults to "GET"
+	URL            string            `json:"url"`             // e.g., "http://billing.internal/invoices"
+	Headers        map[string]string `json:"headers"`         // Request headers
+	Body           json.RawMessage   `json:"body"`            // JSON value or string sent as the request body
+	ExpectedStatus []int             `json:"expected_status"` // Status codes treated as success, defaults to any 2xx
+	Timeout        string            `json:"timeout"`         // e.g., "5m", "30s"
 }
 
 // ExecutionResult contains the output and status of a job execution
@@ -14,4 +32,12 @@ type ExecutionResult struct {
 	Stderr   string
 	ExitCode int32
 	Error    error
+
+	// Set by executors that produce an HTTP response
+	StatusCode int32
+	Headers    map[string][]string
+
+	// Result is the structured output of a successful run, stored on the job
+	// so chained jobs and workflow steps can use it in their payloads
+	Result json.RawMessage
 }
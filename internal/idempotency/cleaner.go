@@ -0,0 +1,49 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/tomiwa-a/Relay/internal/api/app"
+)
+
+const cleanupInterval = time.Hour
+
+// Cleaner deletes idempotency keys whose window has passed. Expired keys are
+// already ignored when a submission claims them, so this only keeps the table
+// from growing.
+type Cleaner struct {
+	app *app.Application
+}
+
+func NewCleaner(app *app.Application) *Cleaner {
+	return &Cleaner{app: app}
+}
+
+func (c *Cleaner) Start(ctx context.Context) {
+	c.app.Logger.Println("starting idempotency key cleaner...")
+
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// Expiry times are stored in UTC when keys are claimed
+			now := pgtype.Timestamp{Time: time.Now().UTC(), Valid: true}
+			deleted, err := c.app.Repository.DeleteExpiredIdempotencyKeys(ctx, now)
+			if err != nil {
+				if ctx.Err() == nil {
+					c.app.Logger.Printf("error deleting expired idempotency keys: %v", err)
+				}
+				continue
+			}
+			if deleted > 0 {
+				c.app.Logger.Printf("deleted %d expired idempotency keys", deleted)
+			}
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/tomiwa-a/Relay/internal/api/app"
+	"github.com/tomiwa-a/Relay/internal/queue"
+	"github.com/tomiwa-a/Relay/internal/repository"
+)
+
+const (
+	batchSize       = 100
+	cleanupInterval = time.Hour
+	retention       = 24 * time.Hour
+)
+
+// Relay publishes outbox messages to the queue.
+// Messages are written in the same transaction as the job change that needs
+// them, so a job is never left pending without a message on its way to a worker.
+// Delivery is at-least-once: a message is marked sent only after Publish succeeds.
+type Relay struct {
+	app *app.Application
+}
+
+func NewRelay(app *app.Application) *Relay {
+	return &Relay{app: app}
+}
+
+func (r *Relay) Start(ctx context.Context) {
+	r.app.Logger.Println("starting outbox relay...")
+
+	ticker := time.NewTicker(r.app.Config.Outbox.PollInterval)
+	defer ticker.Stop()
+
+	lastCleanup := time.Now()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for {
+				n, err := r.flush(ctx)
+				if err != nil {
+					if ctx.Err() == nil {
+						r.app.Logger.Printf("error publishing outbox messages: %v", err)
+					}
+					break
+				}
+				if n < batchSize {
+					break
+				}
+			}
+
+			if time.Since(lastCleanup) >= cleanupInterval {
+				lastCleanup = time.Now()
+				if err := r.app.Repository.DeleteSentOutboxMessages(ctx, retention.Seconds()); err != nil {
+					r.app.Logger.Printf("error deleting sent outbox messages: %v", err)
+				}
+			}
+		}
+	}
+}
+
+// flush publishes one batch of unsent messages and returns how many it sent.
+// Rows are locked with SKIP LOCKED so several relays can run side by side.
+func (r *Relay) flush(ctx context.Context) (int, error) {
+	var n int
+	err := r.app.InTx(ctx, func(q *repository.Queries) error {
+		msgs, err := q.ListUnsentOutboxMessages(ctx, batchSize)
+		if err != nil || len(msgs) == 0 {
+			return err
+		}
+
+		jobs := make([]queue.Job, 0, len(msgs))
+		for _, msg := range msgs {
+			jobs = append(jobs, queue.Job{ID: msg.JobID, Queue: msg.Queue, Priority: msg.Priority, Type: msg.JobType})
+		}
+
+		if err := r.app.Queue.Publish(ctx, jobs...); err != nil {
+			return err
+		}
+
+		for _, msg := range msgs {
+			if err := q.MarkOutboxMessageSent(ctx, msg.ID); err != nil {
+				return err
+			}
+		}
+		n = len(msgs)
+		return nil
+	})
+	return n, err
+}
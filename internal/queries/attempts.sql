@@ -0,0 +1,32 @@
+-- name: CreateJobAttempt :one
+INSERT INTO job_attempts (
+    job_id,
+    attempt,
+    worker_id
+)
+SELECT @job_id::int, COALESCE(MAX(attempt), 0) + 1, @worker_id::text
+FROM job_attempts
+WHERE job_id = @job_id
+RETURNING *;
+
+-- name: FinishJobAttempt :exec
+UPDATE job_attempts
+SET 
+    finished_at = CURRENT_TIMESTAMP,
+    exit_code = $2,
+    error = $3,
+    duration_ms = (EXTRACT(EPOCH FROM (CURRENT_TIMESTAMP::TIMESTAMP - started_at)) * 1000)::BIGINT
+WHERE id = $1;
+
+-- name: AbandonJobAttempts :exec
+UPDATE job_attempts
+SET 
+    finished_at = CURRENT_TIMESTAMP,
+    error = $2,
+    duration_ms = (EXTRACT(EPOCH FROM (CURRENT_TIMESTAMP::TIMESTAMP - started_at)) * 1000)::BIGINT
+WHERE job_id = $1 AND finished_at IS NULL;
+
+-- name: ListJobAttempts :many
+SELECT * FROM job_attempts
+WHERE job_id = $1
+ORDER BY started_at ASC, id ASC;
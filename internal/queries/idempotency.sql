@@ -0,0 +1,30 @@
+-- name: ClaimIdempotencyKey :one
+INSERT INTO idempotency_keys (
+    queue,
+    key,
+    request_hash,
+    expires_at
+) VALUES (
+    @queue, @key, @request_hash, @expires_at
+)
+ON CONFLICT (queue, key) DO UPDATE
+SET 
+    job_id = NULL,
+    request_hash = EXCLUDED.request_hash,
+    created_at = CURRENT_TIMESTAMP,
+    expires_at = EXCLUDED.expires_at
+WHERE idempotency_keys.expires_at <= @now::timestamp
+RETURNING *;
+
+-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys
+WHERE expires_at <= @now::timestamp;
+
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_keys
+WHERE queue = $1 AND key = $2;
+
+-- name: SetIdempotencyKeyJob :exec
+UPDATE idempotency_keys
+SET job_id = $3
+WHERE queue = $1 AND key = $2;
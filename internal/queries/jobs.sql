@@ -5,13 +5,32 @@ INSERT INTO jobs (
     description,
     payload,
     max_retries,
-    timeout_seconds
+    timeout_seconds,
+    status,
+    run_at,
+    schedule_id,
+    retry_strategy,
+    retry_base_delay_seconds,
+    retry_max_delay_seconds,
+    retry_jitter,
+    retry_max_duration_seconds,
+    chain_trigger,
+    workflow_id,
+    step_name,
+    unique_key,
+    priority,
+    queue,
+    rate_limit_key,
+    concurrency_key,
+    concurrency_limit
 ) VALUES (
-    $1, $2, $3, $4, $5, $6
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23
 ) RETURNING *;
 
 -- name: ListJobs :many
 SELECT * FROM jobs
+WHERE (sqlc.narg(priority)::job_priority IS NULL OR priority = sqlc.narg(priority))
+  AND (sqlc.narg(queue)::text IS NULL OR queue = sqlc.narg(queue))
 ORDER BY created_at DESC;
 
 -- name: GetPendingJobs :many
@@ -19,6 +38,132 @@ SELECT * FROM jobs
 WHERE status = 'pending'
 ORDER BY created_at ASC;
 
+-- name: ClaimPendingJob :one
+UPDATE jobs
+SET claimed_until = CURRENT_TIMESTAMP + make_interval(secs => @claim_seconds::int)
+WHERE id = (
+    SELECT j.id FROM jobs j
+    WHERE j.status = 'pending' AND j.queue = @queue AND j.priority = @priority
+      AND (j.claimed_until IS NULL OR j.claimed_until < CURRENT_TIMESTAMP)
+      AND (sqlc.narg(job_types)::text[] IS NULL OR COALESCE(NULLIF(upper(trim(j.payload->>'type')), ''), 'SHELL') = ANY(sqlc.narg(job_types)::text[]))
+      AND NOT EXISTS (
+        SELECT 1 FROM pauses p
+        WHERE (p.scope = 'queue' AND p.name = j.queue)
+           OR (p.scope = 'job_type' AND p.name = COALESCE(NULLIF(upper(trim(j.payload->>'type')), ''), 'SHELL'))
+      )
+    ORDER BY j.created_at ASC
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id;
+
+-- name: StartJob :one
+UPDATE jobs
+SET 
+    status = 'in_progress',
+    claimed_until = NULL,
+    throttles = 0,
+    slot_waits = 0,
+    heartbeat_at = CURRENT_TIMESTAMP,
+    first_attempt_at = COALESCE(first_attempt_at, CURRENT_TIMESTAMP),
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND status = 'pending'
+  AND NOT EXISTS (
+    SELECT 1 FROM pauses p
+    WHERE (p.scope = 'queue' AND p.name = jobs.queue)
+       OR (p.scope = 'job_type' AND p.name = COALESCE(NULLIF(upper(trim(jobs.payload->>'type')), ''), 'SHELL'))
+  )
+RETURNING *;
+
+-- name: HeartbeatJob :one
+UPDATE jobs
+SET heartbeat_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND status = 'in_progress'
+RETURNING cancel_requested_at;
+
+-- name: ListStaleJobs :many
+SELECT * FROM jobs
+WHERE status = 'in_progress'
+  AND COALESCE(heartbeat_at, updated_at) < CURRENT_TIMESTAMP - make_interval(secs => @stale_after_seconds::float8)
+ORDER BY id ASC;
+
+-- name: ReapJob :one
+UPDATE jobs
+SET 
+    status = @status,
+    retries = @retries,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = @id
+  AND status = 'in_progress'
+  AND COALESCE(heartbeat_at, updated_at) < CURRENT_TIMESTAMP - make_interval(secs => @stale_after_seconds::float8)
+RETURNING *;
+
+-- name: CancelPendingJob :one
+UPDATE jobs
+SET 
+    status = 'cancelled',
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND status IN ('pending', 'scheduled', 'failed', 'waiting')
+RETURNING *;
+
+-- name: GetActiveJobByUniqueKey :one
+SELECT * FROM jobs
+WHERE unique_key = $1
+  AND status IN ('scheduled', 'pending', 'in_progress', 'failed');
+
+-- name: RequestJobCancellation :one
+UPDATE jobs
+SET 
+    cancel_requested_at = CURRENT_TIMESTAMP,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND status = 'in_progress'
+RETURNING *;
+
+-- name: ScheduleJobRetry :one
+UPDATE jobs
+SET 
+    status = 'failed',
+    retries = $2,
+    run_at = $3,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING *;
+
+-- name: ListScheduledJobs :many
+SELECT * FROM jobs
+WHERE status IN ('scheduled', 'failed')
+  AND run_at IS NOT NULL
+ORDER BY run_at ASC;
+
+-- name: EnqueueDueJobs :many
+UPDATE jobs
+SET 
+    status = 'pending',
+    updated_at = CURRENT_TIMESTAMP
+WHERE id IN (
+    SELECT id FROM jobs
+    WHERE status IN ('scheduled', 'failed')
+      AND run_at <= @now::timestamp
+      -- Jobs queued behind a still-running run of the same schedule wait their
+      -- turn. Runs put back by a rate limit or concurrency key already had theirs.
+      AND NOT (
+          status = 'scheduled'
+          AND schedule_id IS NOT NULL
+          AND throttles = 0
+          AND slot_waits = 0
+          AND EXISTS (
+              SELECT 1 FROM jobs prev
+              WHERE prev.schedule_id = jobs.schedule_id
+                AND prev.id < jobs.id
+                AND prev.status IN ('scheduled', 'pending', 'in_progress', 'failed')
+          )
+      )
+    ORDER BY run_at ASC
+    LIMIT @batch_size
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id;
+
 -- name: GetJob :one
 SELECT * FROM jobs
 WHERE id = $1;
@@ -28,6 +173,7 @@ UPDATE jobs
 SET 
     status = $2,
     retries = $3,
+    result = $4,
     updated_at = CURRENT_TIMESTAMP
 WHERE id = $1
 RETURNING *;
@@ -39,9 +185,12 @@ INSERT INTO job_logs (
     message,
     stdout,
     stderr,
-    exit_code
+    exit_code,
+    status_code,
+    headers,
+    attempt_id
 ) VALUES (
-    $1, $2, $3, $4, $5, $6
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
 ) RETURNING *;
 
 -- name: GetJobLogs :many
@@ -54,6 +203,107 @@ UPDATE jobs
 SET 
     status = 'pending',
     retries = 0,
+    throttles = 0,
+    slot_waits = 0,
+    cancel_requested_at = NULL,
+    first_attempt_at = NULL,
+    result = NULL,
     updated_at = CURRENT_TIMESTAMP
 WHERE id = $1
-RETURNING *;
\ No newline at end of file
+RETURNING *;
+
+-- name: UpdateJobPayload :exec
+UPDATE jobs
+SET 
+    payload = $2,
+    payload_template = $3,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: HasActiveScheduleJob :one
+SELECT EXISTS (
+    SELECT 1 FROM jobs
+    WHERE schedule_id = $1
+      AND status IN ('scheduled', 'pending', 'in_progress', 'failed')
+);
+
+-- name: ListChildJobs :many
+SELECT * FROM jobs
+WHERE parent_job_id = $1
+ORDER BY id ASC;
+
+-- name: ReleaseChildJobs :many
+UPDATE jobs
+SET 
+    status = 'pending',
+    updated_at = CURRENT_TIMESTAMP
+WHERE parent_job_id = @parent_job_id
+  AND status = 'waiting'
+  AND chain_trigger IN (
+      'on_complete',
+      CASE WHEN @succeeded::boolean THEN 'on_success' ELSE 'on_failure' END::chain_trigger
+  )
+RETURNING id;
+
+-- name: SkipChildJobs :many
+UPDATE jobs
+SET 
+    status = 'skipped',
+    updated_at = CURRENT_TIMESTAMP
+WHERE parent_job_id = $1 AND status = 'waiting'
+RETURNING id;
+
+-- name: ListWorkflowJobs :many
+SELECT * FROM jobs
+WHERE workflow_id = $1
+ORDER BY id ASC;
+
+-- name: ReleaseDependentJobs :many
+UPDATE jobs
+SET 
+    status = 'pending',
+    updated_at = CURRENT_TIMESTAMP
+WHERE status = 'waiting'
+  AND id IN (
+      SELECT job_id FROM job_dependencies
+      WHERE depends_on_job_id = $1
+  )
+  AND NOT EXISTS (
+      SELECT 1 FROM job_dependencies d
+      JOIN jobs parent ON parent.id = d.depends_on_job_id
+      WHERE d.job_id = jobs.id
+        AND parent.status <> 'completed'
+  )
+RETURNING id;
+
+-- name: SkipDependentJobs :many
+UPDATE jobs
+SET 
+    status = 'skipped',
+    updated_at = CURRENT_TIMESTAMP
+WHERE status = 'waiting'
+  AND id IN (
+      SELECT job_id FROM job_dependencies
+      WHERE depends_on_job_id = $1
+  )
+RETURNING id;
+
+-- name: DeferJob :execrows
+UPDATE jobs
+SET 
+    status = 'scheduled',
+    run_at = $2,
+    claimed_until = NULL,
+    slot_waits = slot_waits + 1,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND status = 'pending';
+
+-- name: ThrottleJob :execrows
+UPDATE jobs
+SET 
+    status = 'scheduled',
+    run_at = $2,
+    claimed_until = NULL,
+    throttles = throttles + 1,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND status = 'pending';
@@ -0,0 +1,8 @@
+-- name: TryAdvisoryLock :one
+SELECT pg_try_advisory_lock($1);
+
+-- name: AdvisoryUnlock :exec
+SELECT pg_advisory_unlock($1);
+
+-- name: AdvisoryXactLockKey :exec
+SELECT pg_advisory_xact_lock(hashtextextended(@key::text, 0));
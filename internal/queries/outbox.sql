@@ -0,0 +1,23 @@
+-- name: CreateOutboxMessage :one
+INSERT INTO outbox_messages (
+    job_id
+) VALUES (
+    $1
+) RETURNING *;
+
+-- name: ListUnsentOutboxMessages :many
+SELECT o.id, o.job_id, j.priority, j.queue, COALESCE(NULLIF(upper(trim(j.payload->>'type')), ''), 'SHELL')::text AS job_type FROM outbox_messages o
+JOIN jobs j ON j.id = o.job_id
+WHERE o.sent_at IS NULL
+ORDER BY o.id ASC
+LIMIT $1
+FOR UPDATE OF o SKIP LOCKED;
+
+-- name: MarkOutboxMessageSent :exec
+UPDATE outbox_messages
+SET sent_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: DeleteSentOutboxMessages :exec
+DELETE FROM outbox_messages
+WHERE sent_at < CURRENT_TIMESTAMP - make_interval(secs => @retention_seconds::float8);
@@ -0,0 +1,36 @@
+-- name: CreatePause :one
+INSERT INTO pauses (
+    scope,
+    name
+) VALUES (
+    $1, $2
+)
+ON CONFLICT (scope, name) DO UPDATE
+SET paused_at = pauses.paused_at
+RETURNING *;
+
+-- name: DeletePause :execrows
+DELETE FROM pauses
+WHERE scope = $1 AND name = $2;
+
+-- name: IsPaused :one
+SELECT EXISTS (
+    SELECT 1 FROM pauses
+    WHERE scope = $1 AND name = $2
+);
+
+-- name: ListPauses :many
+SELECT * FROM pauses
+ORDER BY paused_at ASC;
+
+-- name: RequeuePendingJobTypeJobs :execrows
+INSERT INTO outbox_messages (job_id)
+SELECT id FROM jobs
+WHERE status = 'pending' AND COALESCE(NULLIF(upper(trim(payload->>'type')), ''), 'SHELL') = @job_type::text
+ORDER BY id ASC;
+
+-- name: RequeuePendingQueueJobs :execrows
+INSERT INTO outbox_messages (job_id)
+SELECT id FROM jobs
+WHERE status = 'pending' AND queue = $1
+ORDER BY id ASC;
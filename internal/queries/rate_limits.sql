@@ -0,0 +1,33 @@
+-- name: DeleteRateLimit :execrows
+DELETE FROM rate_limits
+WHERE scope = $1 AND name = $2;
+
+-- name: ListJobRateLimits :many
+SELECT r.* FROM rate_limits r
+JOIN jobs j ON j.id = $1 AND j.status = 'pending'
+WHERE (r.scope = 'queue' AND r.name = j.queue)
+   OR (r.scope = 'job_type' AND r.name = COALESCE(NULLIF(upper(trim(j.payload->>'type')), ''), 'SHELL'))
+   OR (r.scope = 'key' AND r.name = j.rate_limit_key)
+ORDER BY r.scope ASC;
+
+-- name: ListRateLimits :many
+SELECT * FROM rate_limits
+ORDER BY scope ASC, name ASC;
+
+-- name: UpsertRateLimit :one
+INSERT INTO rate_limits (
+    scope,
+    name,
+    rate,
+    period_seconds,
+    burst
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+ON CONFLICT (scope, name) DO UPDATE
+SET 
+    rate = EXCLUDED.rate,
+    period_seconds = EXCLUDED.period_seconds,
+    burst = EXCLUDED.burst,
+    updated_at = CURRENT_TIMESTAMP
+RETURNING *;
@@ -0,0 +1,62 @@
+-- name: CreateSchedule :one
+INSERT INTO schedules (
+    name,
+    cron_expression,
+    timezone,
+    title,
+    description,
+    payload,
+    max_retries,
+    timeout_seconds,
+    overlap_policy,
+    catch_up,
+    enabled,
+    next_run_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+) RETURNING *;
+
+-- name: ListSchedules :many
+SELECT * FROM schedules
+ORDER BY created_at DESC;
+
+-- name: GetSchedule :one
+SELECT * FROM schedules
+WHERE id = $1;
+
+-- name: UpdateSchedule :one
+UPDATE schedules
+SET 
+    name = $2,
+    cron_expression = $3,
+    timezone = $4,
+    title = $5,
+    description = $6,
+    payload = $7,
+    max_retries = $8,
+    timeout_seconds = $9,
+    overlap_policy = $10,
+    catch_up = $11,
+    enabled = $12,
+    next_run_at = $13,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteSchedule :execrows
+DELETE FROM schedules
+WHERE id = $1;
+
+-- name: ListDueSchedules :many
+SELECT * FROM schedules
+WHERE enabled
+  AND next_run_at <= @now::timestamp
+ORDER BY next_run_at ASC
+FOR UPDATE SKIP LOCKED;
+
+-- name: AdvanceSchedule :exec
+UPDATE schedules
+SET 
+    next_run_at = $2,
+    last_run_at = $3
+WHERE id = $1;
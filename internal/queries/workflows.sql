@@ -0,0 +1,66 @@
+-- name: CreateWorkflow :one
+INSERT INTO workflows (
+    name
+) VALUES (
+    $1
+) RETURNING *;
+
+-- name: ListWorkflows :many
+SELECT * FROM workflows
+ORDER BY created_at DESC;
+
+-- name: GetWorkflow :one
+SELECT * FROM workflows
+WHERE id = $1;
+
+-- name: LockWorkflow :exec
+SELECT id FROM workflows
+WHERE id = $1
+FOR UPDATE;
+
+-- name: RefreshWorkflowStatus :exec
+UPDATE workflows
+SET 
+    status = CASE
+        WHEN EXISTS (
+            SELECT 1 FROM jobs
+            WHERE workflow_id = workflows.id
+              AND status NOT IN ('completed', 'dead', 'cancelled', 'skipped')
+        ) THEN 'running'
+        WHEN EXISTS (
+            SELECT 1 FROM jobs
+            WHERE workflow_id = workflows.id
+              AND status <> 'completed'
+        ) THEN 'failed'
+        ELSE 'succeeded'
+    END::workflow_status,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: CreateJobDependency :exec
+INSERT INTO job_dependencies (
+    job_id,
+    depends_on_job_id
+) VALUES (
+    $1, $2
+);
+
+-- name: ListWorkflowDependencies :many
+SELECT d.job_id, d.depends_on_job_id FROM job_dependencies d
+JOIN jobs ON jobs.id = d.job_id
+WHERE jobs.workflow_id = $1
+ORDER BY d.job_id ASC, d.depends_on_job_id ASC;
+
+-- name: HasIncompleteDependencies :one
+SELECT EXISTS (
+    SELECT 1 FROM job_dependencies d
+    JOIN jobs ON jobs.id = d.depends_on_job_id
+    WHERE d.job_id = $1
+      AND jobs.status <> 'completed'
+);
+
+-- name: ListDependencyResults :many
+SELECT jobs.step_name, jobs.result FROM job_dependencies d
+JOIN jobs ON jobs.id = d.depends_on_job_id
+WHERE d.job_id = $1
+ORDER BY jobs.id ASC;
@@ -0,0 +1,272 @@
+package queue
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/tomiwa-a/Relay/internal/executor"
+	"github.com/tomiwa-a/Relay/internal/repository"
+)
+
+// typeHeader carries the job type on Kafka messages
+const typeHeader = "type"
+
+// Topic returns the topic that carries a queue's jobs of one priority, built
+// from the base topic: relay-jobs.emails-high for high priority emails jobs.
+// Normal priority jobs in the default queue keep the base topic, so existing
+// deployments carry on as before.
+func Topic(base, queue string, priority repository.JobPriority) string {
+	topic := base
+	if queue != DefaultQueue {
+		topic += "." + queue
+	}
+	if priority != repository.JobPriorityNormal {
+		topic += "-" + string(priority)
+	}
+	return topic
+}
+
+// HandlerTopic returns the topic that carries a queue's jobs of one priority
+// whose type has no built-in executor, such as relay-jobs.emails-high.handlers.
+// Only embedded workers read it, so other workers never fetch a job they can't run.
+func HandlerTopic(base, queue string, priority repository.JobPriority) string {
+	return Topic(base, queue, priority) + ".handlers"
+}
+
+// NewKafkaReaders opens a consumer group reader on each priority's topic of a
+// queue, and on its handler topics as well if withHandlers is set
+func NewKafkaReaders(brokers []string, base, queue, groupID string, withHandlers bool) map[repository.JobPriority][]*kafka.Reader {
+	readers := make(map[repository.JobPriority][]*kafka.Reader, len(Priorities))
+	for _, priority := range Priorities {
+		topics := []string{Topic(base, queue, priority)}
+		if withHandlers {
+			topics = append(topics, HandlerTopic(base, queue, priority))
+		}
+
+		for _, topic := range topics {
+			readers[priority] = append(readers[priority], kafka.NewReader(kafka.ReaderConfig{
+				Brokers: brokers,
+				Topic:   topic,
+				GroupID: groupID,
+			}))
+		}
+	}
+	return readers
+}
+
+// KafkaPublisher writes job IDs to the topic for each job's queue and priority,
+// or its handler topic if the type has no built-in executor, with the job type
+// in a header. Writer must not set a Topic of its own.
+type KafkaPublisher struct {
+	Writer *kafka.Writer
+	Topic  string
+}
+
+func (kp *KafkaPublisher) Publish(ctx context.Context, jobs ...Job) error {
+	msgs := make([]kafka.Message, 0, len(jobs))
+	for _, job := range jobs {
+		topic := Topic(kp.Topic, job.Queue, job.Priority)
+		if !executor.Builtin(job.Type) {
+			topic = HandlerTopic(kp.Topic, job.Queue, job.Priority)
+		}
+
+		msgs = append(msgs, kafka.Message{
+			Topic: topic,
+			Key:   []byte(strconv.Itoa(int(job.ID))),
+			Value: []byte(strconv.Itoa(int(job.ID))),
+			Headers: []kafka.Header{
+				{Key: typeHeader, Value: []byte(job.Type)},
+			},
+		})
+	}
+	return kp.Writer.WriteMessages(ctx, msgs...)
+}
+
+// KafkaConsumer reads the job IDs of one queue, from the topics of each priority.
+// Each reader fetches ahead by a single message, and Fetch picks between the
+// waiting messages in weighted turns, so high priority jobs are taken first
+// without low priority ones waiting forever.
+//
+// Offsets are committed explicitly through Message.Commit. Messages may be
+// committed in any order; a partition's offset only advances past messages
+// that have all been committed, so a slow job is never skipped over.
+type KafkaConsumer struct {
+	Readers map[repository.JobPriority][]*kafka.Reader
+
+	start   sync.Once
+	fetched map[repository.JobPriority]chan fetchedMessage
+	order   weightedOrder
+
+	mu         sync.Mutex
+	partitions map[topicPartition]*partitionOffsets
+}
+
+type fetchedMessage struct {
+	reader  *kafka.Reader
+	message kafka.Message
+	err     error
+}
+
+type topicPartition struct {
+	topic     string
+	partition int
+}
+
+// partitionOffsets tracks the fetched messages of one partition that are not yet committed
+type partitionOffsets struct {
+	inFlight []int64 // fetch order
+	done     map[int64]bool
+}
+
+// Fetch returns the next job. The readers fetch in the background with the
+// context of the first call, so it should be the consumer's lifetime context.
+func (kc *KafkaConsumer) Fetch(ctx context.Context) (Message, error) {
+	kc.start.Do(func() { kc.prefetch(ctx) })
+
+	for {
+		f, err := kc.next(ctx)
+		if err != nil {
+			return Message{}, err
+		}
+
+		m := f.message
+		jobID, err := strconv.Atoi(string(m.Value))
+		if err != nil {
+			// Nothing can ever process it, so acknowledge it and move on
+			if err := kc.commit(ctx, f.reader, m); err != nil {
+				return Message{}, err
+			}
+			continue
+		}
+
+		var jobType string
+		for _, header := range m.Headers {
+			if header.Key == typeHeader {
+				jobType = string(header.Value)
+			}
+		}
+
+		return Message{
+			JobID: int32(jobID),
+			Type:  jobType,
+			commit: func(ctx context.Context) error {
+				return kc.commit(ctx, f.reader, m)
+			},
+		}, nil
+	}
+}
+
+// Close closes every reader
+func (kc *KafkaConsumer) Close() error {
+	var firstErr error
+	for _, readers := range kc.Readers {
+		for _, reader := range readers {
+			if err := reader.Close(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+func (kc *KafkaConsumer) prefetch(ctx context.Context) {
+	kc.fetched = make(map[repository.JobPriority]chan fetchedMessage, len(kc.Readers))
+	for priority, readers := range kc.Readers {
+		ch := make(chan fetchedMessage)
+		kc.fetched[priority] = ch
+
+		for _, reader := range readers {
+			go func() {
+				for {
+					m, err := reader.FetchMessage(ctx)
+					if err == nil {
+						kc.track(m)
+					}
+
+					select {
+					case ch <- fetchedMessage{reader: reader, message: m, err: err}:
+					case <-ctx.Done():
+						return
+					}
+					if err != nil {
+						return
+					}
+				}
+			}()
+		}
+	}
+}
+
+// next takes a waiting message from the first priority in this turn's order
+// that has one. When none are waiting it returns whichever arrives first.
+func (kc *KafkaConsumer) next(ctx context.Context) (fetchedMessage, error) {
+	for _, priority := range kc.order.next() {
+		select {
+		case f := <-kc.fetched[priority]:
+			return f, f.err
+		default:
+		}
+	}
+
+	// A priority without a reader has a nil channel, which never receives
+	var f fetchedMessage
+	select {
+	case <-ctx.Done():
+		return f, ctx.Err()
+	case f = <-kc.fetched[repository.JobPriorityHigh]:
+	case f = <-kc.fetched[repository.JobPriorityNormal]:
+	case f = <-kc.fetched[repository.JobPriorityLow]:
+	}
+	return f, f.err
+}
+
+func (kc *KafkaConsumer) track(m kafka.Message) {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+
+	if kc.partitions == nil {
+		kc.partitions = make(map[topicPartition]*partitionOffsets)
+	}
+
+	key := topicPartition{topic: m.Topic, partition: m.Partition}
+	p, ok := kc.partitions[key]
+	if !ok {
+		p = &partitionOffsets{done: make(map[int64]bool)}
+		kc.partitions[key] = p
+	}
+	p.inFlight = append(p.inFlight, m.Offset)
+}
+
+// committer is the part of a reader that commits offsets
+type committer interface {
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+}
+
+// commit marks m as done and commits the partition up to the oldest message still in flight
+func (kc *KafkaConsumer) commit(ctx context.Context, reader committer, m kafka.Message) error {
+	// Held across CommitMessages so concurrent commits can't land out of order
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+
+	p := kc.partitions[topicPartition{topic: m.Topic, partition: m.Partition}]
+	p.done[m.Offset] = true
+
+	committable := int64(-1)
+	for len(p.inFlight) > 0 && p.done[p.inFlight[0]] {
+		committable = p.inFlight[0]
+		delete(p.done, committable)
+		p.inFlight = p.inFlight[1:]
+	}
+
+	if committable < 0 {
+		return nil
+	}
+
+	return reader.CommitMessages(ctx, kafka.Message{
+		Topic:     m.Topic,
+		Partition: m.Partition,
+		Offset:    committable,
+	})
+}
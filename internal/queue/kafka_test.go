@@ -0,0 +1,81 @@
+package queue
+
+import (
+	"context"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// fakeCommitter records the offsets committed through it
+type fakeCommitter struct {
+	committed []kafka.Message
+}
+
+func (f *fakeCommitter) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	f.committed = append(f.committed, msgs...)
+	return nil
+}
+
+func message(partition int, offset int64) kafka.Message {
+	return kafka.Message{Topic: "relay-jobs", Partition: partition, Offset: offset}
+}
+
+func TestKafkaConsumerCommitsInOrder(t *testing.T) {
+	kc := &KafkaConsumer{}
+	reader := &fakeCommitter{}
+	ctx := context.Background()
+
+	kc.track(message(0, 4))
+	kc.track(message(0, 5))
+
+	// Offset 4 is still running, so committing 5 would skip over it
+	if err := kc.commit(ctx, reader, message(0, 5)); err != nil {
+		t.Fatalf("commit returned error: %v", err)
+	}
+	if len(reader.committed) != 0 {
+		t.Fatalf("committed %v while offset 4 is in flight, want nothing", reader.committed)
+	}
+
+	if err := kc.commit(ctx, reader, message(0, 4)); err != nil {
+		t.Fatalf("commit returned error: %v", err)
+	}
+	if len(reader.committed) != 1 || reader.committed[0].Offset != 5 {
+		t.Fatalf("committed %v, want a single commit of offset 5", reader.committed)
+	}
+}
+
+func TestKafkaConsumerCommitsPartitionsSeparately(t *testing.T) {
+	kc := &KafkaConsumer{}
+	reader := &fakeCommitter{}
+	ctx := context.Background()
+
+	kc.track(message(0, 10))
+	kc.track(message(1, 20))
+	kc.track(message(0, 11))
+
+	// A slow message on partition 0 doesn't hold back partition 1
+	if err := kc.commit(ctx, reader, message(1, 20)); err != nil {
+		t.Fatalf("commit returned error: %v", err)
+	}
+	if len(reader.committed) != 1 || reader.committed[0].Partition != 1 || reader.committed[0].Offset != 20 {
+		t.Fatalf("committed %v, want partition 1 at offset 20", reader.committed)
+	}
+
+	if err := kc.commit(ctx, reader, message(0, 10)); err != nil {
+		t.Fatalf("commit returned error: %v", err)
+	}
+	if err := kc.commit(ctx, reader, message(0, 11)); err != nil {
+		t.Fatalf("commit returned error: %v", err)
+	}
+
+	want := []int64{20, 10, 11}
+	if len(reader.committed) != len(want) {
+		t.Fatalf("committed %v, want offsets %v", reader.committed, want)
+	}
+	for i, offset := range want {
+		if reader.committed[i].Offset != offset {
+			t.Errorf("commit %d has offset %d, want %d", i, reader.committed[i].Offset, offset)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/tomiwa-a/Relay/internal/repository"
+)
+
+// PostgresPublisher is a no-op: a pending row in the jobs table is already
+// visible to PostgresConsumer
+type PostgresPublisher struct{}
+
+func (pp *PostgresPublisher) Publish(ctx context.Context, jobs ...Job) error {
+	return nil
+}
+
+// claimTimeout is how long a claimed job is hidden from other workers. A worker
+// normally starts or defers the job well before then; the claim runs out only
+// if the worker dies between claiming and starting the job.
+const claimTimeout = 30 * time.Second
+
+// PostgresConsumer polls the jobs table for pending jobs in one queue.
+// A job is claimed in a single statement, FOR UPDATE SKIP LOCKED picking a row
+// no other worker holds, so concurrent workers never fetch the same job.
+// The job's status is the acknowledgement, so its messages need no commit.
+// Priorities are checked in weighted turns, as with KafkaConsumer.
+type PostgresConsumer struct {
+	Repository   *repository.Queries
+	PollInterval time.Duration
+	Queue        string
+	JobTypes     []string // the payload types claimed, nil for every type
+
+	order weightedOrder
+}
+
+func (pc *PostgresConsumer) Fetch(ctx context.Context) (Message, error) {
+	for {
+		for _, priority := range pc.order.next() {
+			jobID, err := pc.Repository.ClaimPendingJob(ctx, repository.ClaimPendingJobParams{
+				ClaimSeconds: int32(claimTimeout / time.Second),
+				Queue:        pc.Queue,
+				Priority:     priority,
+				JobTypes:     pc.JobTypes,
+			})
+			if err == nil {
+				return Message{JobID: jobID}, nil
+			}
+			if !errors.Is(err, pgx.ErrNoRows) {
+				return Message{}, err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return Message{}, ctx.Err()
+		case <-time.After(pc.PollInterval):
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package queue
+
+import (
+	"context"
+	"regexp"
+	"sync"
+
+	"github.com/tomiwa-a/Relay/internal/repository"
+)
+
+const (
+	BackendKafka    = "kafka"
+	BackendPostgres = "postgres"
+)
+
+// DefaultQueue takes jobs submitted without a queue
+const DefaultQueue = "default"
+
+var queueNamePattern = regexp.MustCompile(`^[A-Za-z0-9_]{1,64}$`)
+
+// ValidQueueName reports whether a queue name can be used. Names end up in
+// Kafka topic names next to the priority suffix, so they are limited to
+// letters, digits and _.
+func ValidQueueName(name string) bool {
+	return queueNamePattern.MatchString(name)
+}
+
+// Publisher hands jobs to the queue so a worker picks them up
+type Publisher interface {
+	Publish(ctx context.Context, jobs ...Job) error
+}
+
+// Job is a job on its way to a worker
+type Job struct {
+	ID       int32
+	Queue    string
+	Priority repository.JobPriority
+	Type     string // the payload type, as executors match it
+}
+
+// Consumer blocks until a job is available and returns its message.
+// The message stays unacknowledged until Commit is called.
+type Consumer interface {
+	Fetch(ctx context.Context) (Message, error)
+}
+
+// Message is a job delivered by a Consumer
+type Message struct {
+	JobID int32
+	// Type is the job's payload type if the queue carries it, so a worker can
+	// pass over jobs of a paused type without loading them
+	Type   string
+	commit func(ctx context.Context) error
+}
+
+// Commit acknowledges the message so it is not delivered again
+func (m Message) Commit(ctx context.Context) error {
+	if m.commit == nil {
+		return nil
+	}
+	return m.commit(ctx)
+}
+
+// Priorities lists the job priorities from highest to lowest
+var Priorities = []repository.JobPriority{
+	repository.JobPriorityHigh,
+	repository.JobPriorityNormal,
+	repository.JobPriorityLow,
+}
+
+// dispatchCycle gives high, normal and low priority jobs 6, 3 and 1 of every 10
+// turns. The priority whose turn it is gets the first pick and the others
+// follow from highest to lowest, so low priority work still runs once in every
+// 10 fetches however much urgent work is waiting.
+var dispatchCycle = []repository.JobPriority{
+	repository.JobPriorityHigh,
+	repository.JobPriorityNormal,
+	repository.JobPriorityHigh,
+	repository.JobPriorityLow,
+	repository.JobPriorityHigh,
+	repository.JobPriorityNormal,
+	repository.JobPriorityHigh,
+	repository.JobPriorityHigh,
+	repository.JobPriorityNormal,
+	repository.JobPriorityHigh,
+}
+
+// weightedOrder walks dispatchCycle, one turn per fetch
+type weightedOrder struct {
+	mu   sync.Mutex
+	turn int
+}
+
+// next returns the order to look for work in on this fetch
+func (w *weightedOrder) next() []repository.JobPriority {
+	w.mu.Lock()
+	first := dispatchCycle[w.turn]
+	w.turn = (w.turn + 1) % len(dispatchCycle)
+	w.mu.Unlock()
+
+	order := make([]repository.JobPriority, 0, len(Priorities))
+	order = append(order, first)
+	for _, p := range Priorities {
+		if p != first {
+			order = append(order, p)
+		}
+	}
+	return order
+}
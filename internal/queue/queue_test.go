@@ -0,0 +1,106 @@
+package queue
+
+import (
+	"context"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/tomiwa-a/Relay/internal/repository"
+)
+
+func TestWeightedOrderSplit(t *testing.T) {
+	var order weightedOrder
+	first := make(map[repository.JobPriority]int)
+
+	const cycles = 5
+	for range cycles * len(dispatchCycle) {
+		priorities := order.next()
+		if len(priorities) != len(Priorities) {
+			t.Fatalf("order %v doesn't hold every priority", priorities)
+		}
+		first[priorities[0]]++
+	}
+
+	want := map[repository.JobPriority]int{
+		repository.JobPriorityHigh:   6 * cycles,
+		repository.JobPriorityNormal: 3 * cycles,
+		repository.JobPriorityLow:    1 * cycles,
+	}
+	for priority, n := range want {
+		if first[priority] != n {
+			t.Errorf("%s priority went first %d times, want %d", priority, first[priority], n)
+		}
+	}
+}
+
+// consumerWith returns a consumer with n messages of each priority already fetched
+func consumerWith(n map[repository.JobPriority]int) *KafkaConsumer {
+	kc := &KafkaConsumer{fetched: make(map[repository.JobPriority]chan fetchedMessage)}
+	for priority, count := range n {
+		ch := make(chan fetchedMessage, count)
+		for range count {
+			ch <- fetchedMessage{message: kafka.Message{Topic: string(priority)}}
+		}
+		kc.fetched[priority] = ch
+	}
+	return kc
+}
+
+// picks fetches n messages and counts them by priority
+func picks(t *testing.T, kc *KafkaConsumer, n int) map[repository.JobPriority]int {
+	t.Helper()
+	counts := make(map[repository.JobPriority]int)
+	for range n {
+		f, err := kc.next(context.Background())
+		if err != nil {
+			t.Fatalf("next returned error: %v", err)
+		}
+		counts[repository.JobPriority(f.message.Topic)]++
+	}
+	return counts
+}
+
+func TestKafkaConsumerNextWeighted(t *testing.T) {
+	const cycles = 3
+	kc := consumerWith(map[repository.JobPriority]int{
+		repository.JobPriorityHigh:   100,
+		repository.JobPriorityNormal: 100,
+		repository.JobPriorityLow:    100,
+	})
+
+	got := picks(t, kc, cycles*len(dispatchCycle))
+
+	want := map[repository.JobPriority]int{
+		repository.JobPriorityHigh:   6 * cycles,
+		repository.JobPriorityNormal: 3 * cycles,
+		repository.JobPriorityLow:    1 * cycles,
+	}
+	for priority, n := range want {
+		if got[priority] != n {
+			t.Errorf("picked %d %s priority jobs, want %d", got[priority], priority, n)
+		}
+	}
+}
+
+func TestKafkaConsumerNextSkipsEmptyPriorities(t *testing.T) {
+	const cycles = 3
+
+	// Normal's turns go to high, the next priority with work waiting
+	kc := consumerWith(map[repository.JobPriority]int{
+		repository.JobPriorityHigh: 100,
+		repository.JobPriorityLow:  100,
+	})
+	got := picks(t, kc, cycles*len(dispatchCycle))
+	if got[repository.JobPriorityHigh] != 9*cycles || got[repository.JobPriorityLow] != 1*cycles {
+		t.Errorf("picked %v, want %d high and %d low", got, 9*cycles, cycles)
+	}
+
+	// Only low priority work is waiting, so every turn takes it
+	kc = consumerWith(map[repository.JobPriority]int{
+		repository.JobPriorityLow: 100,
+	})
+	got = picks(t, kc, cycles*len(dispatchCycle))
+	if got[repository.JobPriorityLow] != cycles*len(dispatchCycle) {
+		t.Errorf("picked %v, want only low priority jobs", got)
+	}
+}
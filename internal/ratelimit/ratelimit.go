@@ -0,0 +1,104 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/tomiwa-a/Relay/internal/repository"
+)
+
+// takeScript refills each bucket for the time since it was last used and takes
+// a token from every bucket, or from none if any of them is short of one. It
+// returns the milliseconds until all buckets have a token, 0 once taken.
+// KEYS holds the buckets and ARGV the refill rate per millisecond and the burst
+// of each bucket in turn. Redis' clock is used so workers agree on the time.
+var takeScript = redis.NewScript(`
+local clock = redis.call('TIME')
+local now = tonumber(clock[1]) * 1000 + math.floor(tonumber(clock[2]) / 1000)
+
+local tokens = {}
+local wait = 0
+for i, key in ipairs(KEYS) do
+	local rate = tonumber(ARGV[2 * i - 1])
+	local burst = tonumber(ARGV[2 * i])
+	local state = redis.call('HMGET', key, 'tokens', 'ts')
+	local available = tonumber(state[1]) or burst
+	local last = tonumber(state[2]) or now
+	available = math.min(burst, available + math.max(0, now - last) * rate)
+	tokens[i] = available
+	if available < 1 then
+		wait = math.max(wait, math.ceil((1 - available) / rate))
+	end
+end
+
+for i, key in ipairs(KEYS) do
+	local rate = tonumber(ARGV[2 * i - 1])
+	local burst = tonumber(ARGV[2 * i])
+	local available = tokens[i]
+	if wait == 0 then
+		available = available - 1
+	end
+	redis.call('HSET', key, 'tokens', tostring(available), 'ts', now)
+	redis.call('PEXPIRE', key, math.ceil(burst / rate) + 1000)
+end
+
+return wait
+`)
+
+// refundScript puts back a token taken from each bucket, up to its burst.
+// KEYS holds the buckets and ARGV the burst of each bucket in turn.
+var refundScript = redis.NewScript(`
+for i, key in ipairs(KEYS) do
+	local available = tonumber(redis.call('HGET', key, 'tokens'))
+	if available then
+		redis.call('HSET', key, 'tokens', tostring(math.min(tonumber(ARGV[i]), available + 1)))
+	end
+end
+return 0
+`)
+
+// Take takes a token from the bucket of every limit. Buckets live in Redis, so
+// all workers share them. It returns how long until every bucket has a token
+// again if any is empty, in which case nothing is taken.
+func Take(ctx context.Context, rdb *redis.Client, limits []repository.RateLimit) (time.Duration, error) {
+	if len(limits) == 0 {
+		return 0, nil
+	}
+
+	keys := make([]string, 0, len(limits))
+	args := make([]any, 0, 2*len(limits))
+	for _, limit := range limits {
+		perMillisecond := float64(limit.Rate) / float64(int64(limit.PeriodSeconds)*1000)
+		keys = append(keys, bucketKey(limit))
+		args = append(args, strconv.FormatFloat(perMillisecond, 'g', -1, 64), limit.Burst)
+	}
+
+	wait, err := takeScript.Run(ctx, rdb, keys, args...).Int64()
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(wait) * time.Millisecond, nil
+}
+
+// Refund gives back the tokens Take took for limits, for a job that turned out
+// not to start after all
+func Refund(ctx context.Context, rdb *redis.Client, limits []repository.RateLimit) error {
+	if len(limits) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(limits))
+	args := make([]any, 0, len(limits))
+	for _, limit := range limits {
+		keys = append(keys, bucketKey(limit))
+		args = append(args, limit.Burst)
+	}
+	return refundScript.Run(ctx, rdb, keys, args...).Err()
+}
+
+func bucketKey(limit repository.RateLimit) string {
+	return fmt.Sprintf("ratelimit:%s:%s", limit.Scope, limit.Name)
+}
@@ -11,6 +11,97 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const cancelPendingJob = `-- name: CancelPendingJob :one
+UPDATE jobs
+SET 
+    status = 'cancelled',
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND status IN ('pending', 'scheduled', 'failed', 'waiting')
+RETURNING id, parent_job_id, title, description, payload, max_retries, retries, status, created_at, updated_at, timeout_seconds, claimed_until, heartbeat_at, cancel_requested_at, run_at, schedule_id, retry_strategy, retry_base_delay_seconds, retry_max_delay_seconds, retry_jitter, retry_max_duration_seconds, first_attempt_at, chain_trigger, workflow_id, step_name, result, payload_template, unique_key, priority, queue, rate_limit_key, throttles, concurrency_key, concurrency_limit, slot_waits
+`
+
+func (q *Queries) CancelPendingJob(ctx context.Context, id int32) (Job, error) {
+	row := q.db.QueryRow(ctx, cancelPendingJob, id)
+	var i Job
+	err := row.Scan(
+		&i.ID,
+		&i.ParentJobID,
+		&i.Title,
+		&i.Description,
+		&i.Payload,
+		&i.MaxRetries,
+		&i.Retries,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.TimeoutSeconds,
+		&i.ClaimedUntil,
+		&i.HeartbeatAt,
+		&i.CancelRequestedAt,
+		&i.RunAt,
+		&i.ScheduleID,
+		&i.RetryStrategy,
+		&i.RetryBaseDelaySeconds,
+		&i.RetryMaxDelaySeconds,
+		&i.RetryJitter,
+		&i.RetryMaxDurationSeconds,
+		&i.FirstAttemptAt,
+		&i.ChainTrigger,
+		&i.WorkflowID,
+		&i.StepName,
+		&i.Result,
+		&i.PayloadTemplate,
+		&i.UniqueKey,
+		&i.Priority,
+		&i.Queue,
+		&i.RateLimitKey,
+		&i.Throttles,
+		&i.ConcurrencyKey,
+		&i.ConcurrencyLimit,
+		&i.SlotWaits,
+	)
+	return i, err
+}
+
+const claimPendingJob = `-- name: ClaimPendingJob :one
+UPDATE jobs
+SET claimed_until = CURRENT_TIMESTAMP + make_interval(secs => $1::int)
+WHERE id = (
+    SELECT j.id FROM jobs j
+    WHERE j.status = 'pending' AND j.queue = $2 AND j.priority = $3
+      AND (j.claimed_until IS NULL OR j.claimed_until < CURRENT_TIMESTAMP)
+      AND ($4::text[] IS NULL OR COALESCE(NULLIF(upper(trim(j.payload->>'type')), ''), 'SHELL') = ANY($4::text[]))
+      AND NOT EXISTS (
+        SELECT 1 FROM pauses p
+        WHERE (p.scope = 'queue' AND p.name = j.queue)
+           OR (p.scope = 'job_type' AND p.name = COALESCE(NULLIF(upper(trim(j.payload->>'type')), ''), 'SHELL'))
+      )
+    ORDER BY j.created_at ASC
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id
+`
+
+type ClaimPendingJobParams struct {
+	ClaimSeconds int32
+	Queue        string
+	Priority     JobPriority
+	JobTypes     []string
+}
+
+func (q *Queries) ClaimPendingJob(ctx context.Context, arg ClaimPendingJobParams) (int32, error) {
+	row := q.db.QueryRow(ctx, claimPendingJob,
+		arg.ClaimSeconds,
+		arg.Queue,
+		arg.Priority,
+		arg.JobTypes,
+	)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
+
 const createJob = `-- name: CreateJob :one
 INSERT INTO jobs (
     parent_job_id,
@@ -18,19 +109,53 @@ INSERT INTO jobs (
     description,
     payload,
     max_retries,
-    timeout_seconds
+    timeout_seconds,
+    status,
+    run_at,
+    schedule_id,
+    retry_strategy,
+    retry_base_delay_seconds,
+    retry_max_delay_seconds,
+    retry_jitter,
+    retry_max_duration_seconds,
+    chain_trigger,
+    workflow_id,
+    step_name,
+    unique_key,
+    priority,
+    queue,
+    rate_limit_key,
+    concurrency_key,
+    concurrency_limit
 ) VALUES (
-    $1, $2, $3, $4, $5, $6
-) RETURNING id, parent_job_id, title, description, payload, max_retries, retries, status, created_at, updated_at, timeout_seconds
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23
+) RETURNING id, parent_job_id, title, description, payload, max_retries, retries, status, created_at, updated_at, timeout_seconds, claimed_until, heartbeat_at, cancel_requested_at, run_at, schedule_id, retry_strategy, retry_base_delay_seconds, retry_max_delay_seconds, retry_jitter, retry_max_duration_seconds, first_attempt_at, chain_trigger, workflow_id, step_name, result, payload_template, unique_key, priority, queue, rate_limit_key, throttles, concurrency_key, concurrency_limit, slot_waits
 `
 
 type CreateJobParams struct {
-	ParentJobID    pgtype.Int4
-	Title          string
-	Description    pgtype.Text
-	Payload        []byte
-	MaxRetries     pgtype.Int4
-	TimeoutSeconds pgtype.Int4
+	ParentJobID             pgtype.Int4
+	Title                   string
+	Description             pgtype.Text
+	Payload                 []byte
+	MaxRetries              pgtype.Int4
+	TimeoutSeconds          pgtype.Int4
+	Status                  NullJobStatus
+	RunAt                   pgtype.Timestamp
+	ScheduleID              pgtype.Int4
+	RetryStrategy           RetryStrategy
+	RetryBaseDelaySeconds   int32
+	RetryMaxDelaySeconds    pgtype.Int4
+	RetryJitter             bool
+	RetryMaxDurationSeconds pgtype.Int4
+	ChainTrigger            NullChainTrigger
+	WorkflowID              pgtype.Int4
+	StepName                pgtype.Text
+	UniqueKey               pgtype.Text
+	Priority                JobPriority
+	Queue                   string
+	RateLimitKey            pgtype.Text
+	ConcurrencyKey          pgtype.Text
+	ConcurrencyLimit        pgtype.Int4
 }
 
 func (q *Queries) CreateJob(ctx context.Context, arg CreateJobParams) (Job, error) {
@@ -41,6 +166,23 @@ func (q *Queries) CreateJob(ctx context.Context, arg CreateJobParams) (Job, erro
 		arg.Payload,
 		arg.MaxRetries,
 		arg.TimeoutSeconds,
+		arg.Status,
+		arg.RunAt,
+		arg.ScheduleID,
+		arg.RetryStrategy,
+		arg.RetryBaseDelaySeconds,
+		arg.RetryMaxDelaySeconds,
+		arg.RetryJitter,
+		arg.RetryMaxDurationSeconds,
+		arg.ChainTrigger,
+		arg.WorkflowID,
+		arg.StepName,
+		arg.UniqueKey,
+		arg.Priority,
+		arg.Queue,
+		arg.RateLimitKey,
+		arg.ConcurrencyKey,
+		arg.ConcurrencyLimit,
 	)
 	var i Job
 	err := row.Scan(
@@ -55,6 +197,30 @@ func (q *Queries) CreateJob(ctx context.Context, arg CreateJobParams) (Job, erro
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.TimeoutSeconds,
+		&i.ClaimedUntil,
+		&i.HeartbeatAt,
+		&i.CancelRequestedAt,
+		&i.RunAt,
+		&i.ScheduleID,
+		&i.RetryStrategy,
+		&i.RetryBaseDelaySeconds,
+		&i.RetryMaxDelaySeconds,
+		&i.RetryJitter,
+		&i.RetryMaxDurationSeconds,
+		&i.FirstAttemptAt,
+		&i.ChainTrigger,
+		&i.WorkflowID,
+		&i.StepName,
+		&i.Result,
+		&i.PayloadTemplate,
+		&i.UniqueKey,
+		&i.Priority,
+		&i.Queue,
+		&i.RateLimitKey,
+		&i.Throttles,
+		&i.ConcurrencyKey,
+		&i.ConcurrencyLimit,
+		&i.SlotWaits,
 	)
 	return i, err
 }
@@ -66,19 +232,25 @@ INSERT INTO job_logs (
     message,
     stdout,
     stderr,
-    exit_code
+    exit_code,
+    status_code,
+    headers,
+    attempt_id
 ) VALUES (
-    $1, $2, $3, $4, $5, $6
-) RETURNING id, job_id, stdout, stderr, exit_code, created_at, level, message
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+) RETURNING id, job_id, stdout, stderr, exit_code, created_at, level, message, status_code, headers, attempt_id
 `
 
 type CreateJobLogParams struct {
-	JobID    int32
-	Level    LogLevel
-	Message  string
-	Stdout   pgtype.Text
-	Stderr   pgtype.Text
-	ExitCode pgtype.Int4
+	JobID      int32
+	Level      LogLevel
+	Message    string
+	Stdout     pgtype.Text
+	Stderr     pgtype.Text
+	ExitCode   pgtype.Int4
+	StatusCode pgtype.Int4
+	Headers    []byte
+	AttemptID  pgtype.Int4
 }
 
 func (q *Queries) CreateJobLog(ctx context.Context, arg CreateJobLogParams) (JobLog, error) {
@@ -89,6 +261,9 @@ func (q *Queries) CreateJobLog(ctx context.Context, arg CreateJobLogParams) (Job
 		arg.Stdout,
 		arg.Stderr,
 		arg.ExitCode,
+		arg.StatusCode,
+		arg.Headers,
+		arg.AttemptID,
 	)
 	var i JobLog
 	err := row.Scan(
@@ -100,12 +275,143 @@ func (q *Queries) CreateJobLog(ctx context.Context, arg CreateJobLogParams) (Job
 		&i.CreatedAt,
 		&i.Level,
 		&i.Message,
+		&i.StatusCode,
+		&i.Headers,
+		&i.AttemptID,
+	)
+	return i, err
+}
+
+const deferJob = `-- name: DeferJob :execrows
+UPDATE jobs
+SET 
+    status = 'scheduled',
+    run_at = $2,
+    claimed_until = NULL,
+    slot_waits = slot_waits + 1,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND status = 'pending'
+`
+
+type DeferJobParams struct {
+	ID    int32
+	RunAt pgtype.Timestamp
+}
+
+func (q *Queries) DeferJob(ctx context.Context, arg DeferJobParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deferJob, arg.ID, arg.RunAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const enqueueDueJobs = `-- name: EnqueueDueJobs :many
+UPDATE jobs
+SET 
+    status = 'pending',
+    updated_at = CURRENT_TIMESTAMP
+WHERE id IN (
+    SELECT id FROM jobs
+    WHERE status IN ('scheduled', 'failed')
+      AND run_at <= $1::timestamp
+      -- Jobs queued behind a still-running run of the same schedule wait their
+      -- turn. Runs put back by a rate limit or concurrency key already had theirs.
+      AND NOT (
+          status = 'scheduled'
+          AND schedule_id IS NOT NULL
+          AND throttles = 0
+          AND slot_waits = 0
+          AND EXISTS (
+              SELECT 1 FROM jobs prev
+              WHERE prev.schedule_id = jobs.schedule_id
+                AND prev.id < jobs.id
+                AND prev.status IN ('scheduled', 'pending', 'in_progress', 'failed')
+          )
+      )
+    ORDER BY run_at ASC
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id
+`
+
+type EnqueueDueJobsParams struct {
+	Now       pgtype.Timestamp
+	BatchSize int32
+}
+
+func (q *Queries) EnqueueDueJobs(ctx context.Context, arg EnqueueDueJobsParams) ([]int32, error) {
+	rows, err := q.db.Query(ctx, enqueueDueJobs, arg.Now, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getActiveJobByUniqueKey = `-- name: GetActiveJobByUniqueKey :one
+SELECT id, parent_job_id, title, description, payload, max_retries, retries, status, created_at, updated_at, timeout_seconds, claimed_until, heartbeat_at, cancel_requested_at, run_at, schedule_id, retry_strategy, retry_base_delay_seconds, retry_max_delay_seconds, retry_jitter, retry_max_duration_seconds, first_attempt_at, chain_trigger, workflow_id, step_name, result, payload_template, unique_key, priority, queue, rate_limit_key, throttles, concurrency_key, concurrency_limit, slot_waits FROM jobs
+WHERE unique_key = $1
+  AND status IN ('scheduled', 'pending', 'in_progress', 'failed')
+`
+
+func (q *Queries) GetActiveJobByUniqueKey(ctx context.Context, uniqueKey pgtype.Text) (Job, error) {
+	row := q.db.QueryRow(ctx, getActiveJobByUniqueKey, uniqueKey)
+	var i Job
+	err := row.Scan(
+		&i.ID,
+		&i.ParentJobID,
+		&i.Title,
+		&i.Description,
+		&i.Payload,
+		&i.MaxRetries,
+		&i.Retries,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.TimeoutSeconds,
+		&i.ClaimedUntil,
+		&i.HeartbeatAt,
+		&i.CancelRequestedAt,
+		&i.RunAt,
+		&i.ScheduleID,
+		&i.RetryStrategy,
+		&i.RetryBaseDelaySeconds,
+		&i.RetryMaxDelaySeconds,
+		&i.RetryJitter,
+		&i.RetryMaxDurationSeconds,
+		&i.FirstAttemptAt,
+		&i.ChainTrigger,
+		&i.WorkflowID,
+		&i.StepName,
+		&i.Result,
+		&i.PayloadTemplate,
+		&i.UniqueKey,
+		&i.Priority,
+		&i.Queue,
+		&i.RateLimitKey,
+		&i.Throttles,
+		&i.ConcurrencyKey,
+		&i.ConcurrencyLimit,
+		&i.SlotWaits,
 	)
 	return i, err
 }
 
 const getJob = `-- name: GetJob :one
-SELECT id, parent_job_id, title, description, payload, max_retries, retries, status, created_at, updated_at, timeout_seconds FROM jobs
+SELECT id, parent_job_id, title, description, payload, max_retries, retries, status, created_at, updated_at, timeout_seconds, claimed_until, heartbeat_at, cancel_requested_at, run_at, schedule_id, retry_strategy, retry_base_delay_seconds, retry_max_delay_seconds, retry_jitter, retry_max_duration_seconds, first_attempt_at, chain_trigger, workflow_id, step_name, result, payload_template, unique_key, priority, queue, rate_limit_key, throttles, concurrency_key, concurrency_limit, slot_waits FROM jobs
 WHERE id = $1
 `
 
@@ -124,12 +430,36 @@ func (q *Queries) GetJob(ctx context.Context, id int32) (Job, error) {
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.TimeoutSeconds,
+		&i.ClaimedUntil,
+		&i.HeartbeatAt,
+		&i.CancelRequestedAt,
+		&i.RunAt,
+		&i.ScheduleID,
+		&i.RetryStrategy,
+		&i.RetryBaseDelaySeconds,
+		&i.RetryMaxDelaySeconds,
+		&i.RetryJitter,
+		&i.RetryMaxDurationSeconds,
+		&i.FirstAttemptAt,
+		&i.ChainTrigger,
+		&i.WorkflowID,
+		&i.StepName,
+		&i.Result,
+		&i.PayloadTemplate,
+		&i.UniqueKey,
+		&i.Priority,
+		&i.Queue,
+		&i.RateLimitKey,
+		&i.Throttles,
+		&i.ConcurrencyKey,
+		&i.ConcurrencyLimit,
+		&i.SlotWaits,
 	)
 	return i, err
 }
 
 const getJobLogs = `-- name: GetJobLogs :many
-SELECT id, job_id, stdout, stderr, exit_code, created_at, level, message FROM job_logs
+SELECT id, job_id, stdout, stderr, exit_code, created_at, level, message, status_code, headers, attempt_id FROM job_logs
 WHERE job_id = $1
 ORDER BY created_at ASC
 `
@@ -152,6 +482,9 @@ func (q *Queries) GetJobLogs(ctx context.Context, jobID int32) ([]JobLog, error)
 			&i.CreatedAt,
 			&i.Level,
 			&i.Message,
+			&i.StatusCode,
+			&i.Headers,
+			&i.AttemptID,
 		); err != nil {
 			return nil, err
 		}
@@ -164,7 +497,7 @@ func (q *Queries) GetJobLogs(ctx context.Context, jobID int32) ([]JobLog, error)
 }
 
 const getPendingJobs = `-- name: GetPendingJobs :many
-SELECT id, parent_job_id, title, description, payload, max_retries, retries, status, created_at, updated_at, timeout_seconds FROM jobs
+SELECT id, parent_job_id, title, description, payload, max_retries, retries, status, created_at, updated_at, timeout_seconds, claimed_until, heartbeat_at, cancel_requested_at, run_at, schedule_id, retry_strategy, retry_base_delay_seconds, retry_max_delay_seconds, retry_jitter, retry_max_duration_seconds, first_attempt_at, chain_trigger, workflow_id, step_name, result, payload_template, unique_key, priority, queue, rate_limit_key, throttles, concurrency_key, concurrency_limit, slot_waits FROM jobs
 WHERE status = 'pending'
 ORDER BY created_at ASC
 `
@@ -190,6 +523,121 @@ func (q *Queries) GetPendingJobs(ctx context.Context) ([]Job, error) {
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.TimeoutSeconds,
+			&i.ClaimedUntil,
+			&i.HeartbeatAt,
+			&i.CancelRequestedAt,
+			&i.RunAt,
+			&i.ScheduleID,
+			&i.RetryStrategy,
+			&i.RetryBaseDelaySeconds,
+			&i.RetryMaxDelaySeconds,
+			&i.RetryJitter,
+			&i.RetryMaxDurationSeconds,
+			&i.FirstAttemptAt,
+			&i.ChainTrigger,
+			&i.WorkflowID,
+			&i.StepName,
+			&i.Result,
+			&i.PayloadTemplate,
+			&i.UniqueKey,
+			&i.Priority,
+			&i.Queue,
+			&i.RateLimitKey,
+			&i.Throttles,
+			&i.ConcurrencyKey,
+			&i.ConcurrencyLimit,
+			&i.SlotWaits,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const hasActiveScheduleJob = `-- name: HasActiveScheduleJob :one
+SELECT EXISTS (
+    SELECT 1 FROM jobs
+    WHERE schedule_id = $1
+      AND status IN ('scheduled', 'pending', 'in_progress', 'failed')
+)
+`
+
+func (q *Queries) HasActiveScheduleJob(ctx context.Context, scheduleID pgtype.Int4) (bool, error) {
+	row := q.db.QueryRow(ctx, hasActiveScheduleJob, scheduleID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const heartbeatJob = `-- name: HeartbeatJob :one
+UPDATE jobs
+SET heartbeat_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND status = 'in_progress'
+RETURNING cancel_requested_at
+`
+
+func (q *Queries) HeartbeatJob(ctx context.Context, id int32) (pgtype.Timestamp, error) {
+	row := q.db.QueryRow(ctx, heartbeatJob, id)
+	var cancel_requested_at pgtype.Timestamp
+	err := row.Scan(&cancel_requested_at)
+	return cancel_requested_at, err
+}
+
+const listChildJobs = `-- name: ListChildJobs :many
+SELECT id, parent_job_id, title, description, payload, max_retries, retries, status, created_at, updated_at, timeout_seconds, claimed_until, heartbeat_at, cancel_requested_at, run_at, schedule_id, retry_strategy, retry_base_delay_seconds, retry_max_delay_seconds, retry_jitter, retry_max_duration_seconds, first_attempt_at, chain_trigger, workflow_id, step_name, result, payload_template, unique_key, priority, queue, rate_limit_key, throttles, concurrency_key, concurrency_limit, slot_waits FROM jobs
+WHERE parent_job_id = $1
+ORDER BY id ASC
+`
+
+func (q *Queries) ListChildJobs(ctx context.Context, parentJobID pgtype.Int4) ([]Job, error) {
+	rows, err := q.db.Query(ctx, listChildJobs, parentJobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Job
+	for rows.Next() {
+		var i Job
+		if err := rows.Scan(
+			&i.ID,
+			&i.ParentJobID,
+			&i.Title,
+			&i.Description,
+			&i.Payload,
+			&i.MaxRetries,
+			&i.Retries,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.TimeoutSeconds,
+			&i.ClaimedUntil,
+			&i.HeartbeatAt,
+			&i.CancelRequestedAt,
+			&i.RunAt,
+			&i.ScheduleID,
+			&i.RetryStrategy,
+			&i.RetryBaseDelaySeconds,
+			&i.RetryMaxDelaySeconds,
+			&i.RetryJitter,
+			&i.RetryMaxDurationSeconds,
+			&i.FirstAttemptAt,
+			&i.ChainTrigger,
+			&i.WorkflowID,
+			&i.StepName,
+			&i.Result,
+			&i.PayloadTemplate,
+			&i.UniqueKey,
+			&i.Priority,
+			&i.Queue,
+			&i.RateLimitKey,
+			&i.Throttles,
+			&i.ConcurrencyKey,
+			&i.ConcurrencyLimit,
+			&i.SlotWaits,
 		); err != nil {
 			return nil, err
 		}
@@ -202,12 +650,207 @@ func (q *Queries) GetPendingJobs(ctx context.Context) ([]Job, error) {
 }
 
 const listJobs = `-- name: ListJobs :many
-SELECT id, parent_job_id, title, description, payload, max_retries, retries, status, created_at, updated_at, timeout_seconds FROM jobs
+SELECT id, parent_job_id, title, description, payload, max_retries, retries, status, created_at, updated_at, timeout_seconds, claimed_until, heartbeat_at, cancel_requested_at, run_at, schedule_id, retry_strategy, retry_base_delay_seconds, retry_max_delay_seconds, retry_jitter, retry_max_duration_seconds, first_attempt_at, chain_trigger, workflow_id, step_name, result, payload_template, unique_key, priority, queue, rate_limit_key, throttles, concurrency_key, concurrency_limit, slot_waits FROM jobs
+WHERE ($1::job_priority IS NULL OR priority = $1)
+  AND ($2::text IS NULL OR queue = $2)
 ORDER BY created_at DESC
 `
 
-func (q *Queries) ListJobs(ctx context.Context) ([]Job, error) {
-	rows, err := q.db.Query(ctx, listJobs)
+type ListJobsParams struct {
+	Priority NullJobPriority
+	Queue    pgtype.Text
+}
+
+func (q *Queries) ListJobs(ctx context.Context, arg ListJobsParams) ([]Job, error) {
+	rows, err := q.db.Query(ctx, listJobs, arg.Priority, arg.Queue)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Job
+	for rows.Next() {
+		var i Job
+		if err := rows.Scan(
+			&i.ID,
+			&i.ParentJobID,
+			&i.Title,
+			&i.Description,
+			&i.Payload,
+			&i.MaxRetries,
+			&i.Retries,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.TimeoutSeconds,
+			&i.ClaimedUntil,
+			&i.HeartbeatAt,
+			&i.CancelRequestedAt,
+			&i.RunAt,
+			&i.ScheduleID,
+			&i.RetryStrategy,
+			&i.RetryBaseDelaySeconds,
+			&i.RetryMaxDelaySeconds,
+			&i.RetryJitter,
+			&i.RetryMaxDurationSeconds,
+			&i.FirstAttemptAt,
+			&i.ChainTrigger,
+			&i.WorkflowID,
+			&i.StepName,
+			&i.Result,
+			&i.PayloadTemplate,
+			&i.UniqueKey,
+			&i.Priority,
+			&i.Queue,
+			&i.RateLimitKey,
+			&i.Throttles,
+			&i.ConcurrencyKey,
+			&i.ConcurrencyLimit,
+			&i.SlotWaits,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listScheduledJobs = `-- name: ListScheduledJobs :many
+SELECT id, parent_job_id, title, description, payload, max_retries, retries, status, created_at, updated_at, timeout_seconds, claimed_until, heartbeat_at, cancel_requested_at, run_at, schedule_id, retry_strategy, retry_base_delay_seconds, retry_max_delay_seconds, retry_jitter, retry_max_duration_seconds, first_attempt_at, chain_trigger, workflow_id, step_name, result, payload_template, unique_key, priority, queue, rate_limit_key, throttles, concurrency_key, concurrency_limit, slot_waits FROM jobs
+WHERE status IN ('scheduled', 'failed')
+  AND run_at IS NOT NULL
+ORDER BY run_at ASC
+`
+
+func (q *Queries) ListScheduledJobs(ctx context.Context) ([]Job, error) {
+	rows, err := q.db.Query(ctx, listScheduledJobs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Job
+	for rows.Next() {
+		var i Job
+		if err := rows.Scan(
+			&i.ID,
+			&i.ParentJobID,
+			&i.Title,
+			&i.Description,
+			&i.Payload,
+			&i.MaxRetries,
+			&i.Retries,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.TimeoutSeconds,
+			&i.ClaimedUntil,
+			&i.HeartbeatAt,
+			&i.CancelRequestedAt,
+			&i.RunAt,
+			&i.ScheduleID,
+			&i.RetryStrategy,
+			&i.RetryBaseDelaySeconds,
+			&i.RetryMaxDelaySeconds,
+			&i.RetryJitter,
+			&i.RetryMaxDurationSeconds,
+			&i.FirstAttemptAt,
+			&i.ChainTrigger,
+			&i.WorkflowID,
+			&i.StepName,
+			&i.Result,
+			&i.PayloadTemplate,
+			&i.UniqueKey,
+			&i.Priority,
+			&i.Queue,
+			&i.RateLimitKey,
+			&i.Throttles,
+			&i.ConcurrencyKey,
+			&i.ConcurrencyLimit,
+			&i.SlotWaits,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listStaleJobs = `-- name: ListStaleJobs :many
+SELECT id, parent_job_id, title, description, payload, max_retries, retries, status, created_at, updated_at, timeout_seconds, claimed_until, heartbeat_at, cancel_requested_at, run_at, schedule_id, retry_strategy, retry_base_delay_seconds, retry_max_delay_seconds, retry_jitter, retry_max_duration_seconds, first_attempt_at, chain_trigger, workflow_id, step_name, result, payload_template, unique_key, priority, queue, rate_limit_key, throttles, concurrency_key, concurrency_limit, slot_waits FROM jobs
+WHERE status = 'in_progress'
+  AND COALESCE(heartbeat_at, updated_at) < CURRENT_TIMESTAMP - make_interval(secs => $1::float8)
+ORDER BY id ASC
+`
+
+func (q *Queries) ListStaleJobs(ctx context.Context, staleAfterSeconds float64) ([]Job, error) {
+	rows, err := q.db.Query(ctx, listStaleJobs, staleAfterSeconds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Job
+	for rows.Next() {
+		var i Job
+		if err := rows.Scan(
+			&i.ID,
+			&i.ParentJobID,
+			&i.Title,
+			&i.Description,
+			&i.Payload,
+			&i.MaxRetries,
+			&i.Retries,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.TimeoutSeconds,
+			&i.ClaimedUntil,
+			&i.HeartbeatAt,
+			&i.CancelRequestedAt,
+			&i.RunAt,
+			&i.ScheduleID,
+			&i.RetryStrategy,
+			&i.RetryBaseDelaySeconds,
+			&i.RetryMaxDelaySeconds,
+			&i.RetryJitter,
+			&i.RetryMaxDurationSeconds,
+			&i.FirstAttemptAt,
+			&i.ChainTrigger,
+			&i.WorkflowID,
+			&i.StepName,
+			&i.Result,
+			&i.PayloadTemplate,
+			&i.UniqueKey,
+			&i.Priority,
+			&i.Queue,
+			&i.RateLimitKey,
+			&i.Throttles,
+			&i.ConcurrencyKey,
+			&i.ConcurrencyLimit,
+			&i.SlotWaits,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWorkflowJobs = `-- name: ListWorkflowJobs :many
+SELECT id, parent_job_id, title, description, payload, max_retries, retries, status, created_at, updated_at, timeout_seconds, claimed_until, heartbeat_at, cancel_requested_at, run_at, schedule_id, retry_strategy, retry_base_delay_seconds, retry_max_delay_seconds, retry_jitter, retry_max_duration_seconds, first_attempt_at, chain_trigger, workflow_id, step_name, result, payload_template, unique_key, priority, queue, rate_limit_key, throttles, concurrency_key, concurrency_limit, slot_waits FROM jobs
+WHERE workflow_id = $1
+ORDER BY id ASC
+`
+
+func (q *Queries) ListWorkflowJobs(ctx context.Context, workflowID pgtype.Int4) ([]Job, error) {
+	rows, err := q.db.Query(ctx, listWorkflowJobs, workflowID)
 	if err != nil {
 		return nil, err
 	}
@@ -227,6 +870,30 @@ func (q *Queries) ListJobs(ctx context.Context) ([]Job, error) {
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.TimeoutSeconds,
+			&i.ClaimedUntil,
+			&i.HeartbeatAt,
+			&i.CancelRequestedAt,
+			&i.RunAt,
+			&i.ScheduleID,
+			&i.RetryStrategy,
+			&i.RetryBaseDelaySeconds,
+			&i.RetryMaxDelaySeconds,
+			&i.RetryJitter,
+			&i.RetryMaxDurationSeconds,
+			&i.FirstAttemptAt,
+			&i.ChainTrigger,
+			&i.WorkflowID,
+			&i.StepName,
+			&i.Result,
+			&i.PayloadTemplate,
+			&i.UniqueKey,
+			&i.Priority,
+			&i.Queue,
+			&i.RateLimitKey,
+			&i.Throttles,
+			&i.ConcurrencyKey,
+			&i.ConcurrencyLimit,
+			&i.SlotWaits,
 		); err != nil {
 			return nil, err
 		}
@@ -238,14 +905,164 @@ func (q *Queries) ListJobs(ctx context.Context) ([]Job, error) {
 	return items, nil
 }
 
+const reapJob = `-- name: ReapJob :one
+UPDATE jobs
+SET 
+    status = $1,
+    retries = $2,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $3
+  AND status = 'in_progress'
+  AND COALESCE(heartbeat_at, updated_at) < CURRENT_TIMESTAMP - make_interval(secs => $4::float8)
+RETURNING id, parent_job_id, title, description, payload, max_retries, retries, status, created_at, updated_at, timeout_seconds, claimed_until, heartbeat_at, cancel_requested_at, run_at, schedule_id, retry_strategy, retry_base_delay_seconds, retry_max_delay_seconds, retry_jitter, retry_max_duration_seconds, first_attempt_at, chain_trigger, workflow_id, step_name, result, payload_template, unique_key, priority, queue, rate_limit_key, throttles, concurrency_key, concurrency_limit, slot_waits
+`
+
+type ReapJobParams struct {
+	Status            NullJobStatus
+	Retries           pgtype.Int4
+	ID                int32
+	StaleAfterSeconds float64
+}
+
+func (q *Queries) ReapJob(ctx context.Context, arg ReapJobParams) (Job, error) {
+	row := q.db.QueryRow(ctx, reapJob,
+		arg.Status,
+		arg.Retries,
+		arg.ID,
+		arg.StaleAfterSeconds,
+	)
+	var i Job
+	err := row.Scan(
+		&i.ID,
+		&i.ParentJobID,
+		&i.Title,
+		&i.Description,
+		&i.Payload,
+		&i.MaxRetries,
+		&i.Retries,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.TimeoutSeconds,
+		&i.ClaimedUntil,
+		&i.HeartbeatAt,
+		&i.CancelRequestedAt,
+		&i.RunAt,
+		&i.ScheduleID,
+		&i.RetryStrategy,
+		&i.RetryBaseDelaySeconds,
+		&i.RetryMaxDelaySeconds,
+		&i.RetryJitter,
+		&i.RetryMaxDurationSeconds,
+		&i.FirstAttemptAt,
+		&i.ChainTrigger,
+		&i.WorkflowID,
+		&i.StepName,
+		&i.Result,
+		&i.PayloadTemplate,
+		&i.UniqueKey,
+		&i.Priority,
+		&i.Queue,
+		&i.RateLimitKey,
+		&i.Throttles,
+		&i.ConcurrencyKey,
+		&i.ConcurrencyLimit,
+		&i.SlotWaits,
+	)
+	return i, err
+}
+
+const releaseChildJobs = `-- name: ReleaseChildJobs :many
+UPDATE jobs
+SET 
+    status = 'pending',
+    updated_at = CURRENT_TIMESTAMP
+WHERE parent_job_id = $1
+  AND status = 'waiting'
+  AND chain_trigger IN (
+      'on_complete',
+      CASE WHEN $2::boolean THEN 'on_success' ELSE 'on_failure' END::chain_trigger
+  )
+RETURNING id
+`
+
+type ReleaseChildJobsParams struct {
+	ParentJobID pgtype.Int4
+	Succeeded   bool
+}
+
+func (q *Queries) ReleaseChildJobs(ctx context.Context, arg ReleaseChildJobsParams) ([]int32, error) {
+	rows, err := q.db.Query(ctx, releaseChildJobs, arg.ParentJobID, arg.Succeeded)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const releaseDependentJobs = `-- name: ReleaseDependentJobs :many
+UPDATE jobs
+SET 
+    status = 'pending',
+    updated_at = CURRENT_TIMESTAMP
+WHERE status = 'waiting'
+  AND id IN (
+      SELECT job_id FROM job_dependencies
+      WHERE depends_on_job_id = $1
+  )
+  AND NOT EXISTS (
+      SELECT 1 FROM job_dependencies d
+      JOIN jobs parent ON parent.id = d.depends_on_job_id
+      WHERE d.job_id = jobs.id
+        AND parent.status <> 'completed'
+  )
+RETURNING id
+`
+
+func (q *Queries) ReleaseDependentJobs(ctx context.Context, dependsOnJobID int32) ([]int32, error) {
+	rows, err := q.db.Query(ctx, releaseDependentJobs, dependsOnJobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const replayJob = `-- name: ReplayJob :one
 UPDATE jobs
 SET 
     status = 'pending',
     retries = 0,
+    throttles = 0,
+    slot_waits = 0,
+    cancel_requested_at = NULL,
+    first_attempt_at = NULL,
+    result = NULL,
     updated_at = CURRENT_TIMESTAMP
 WHERE id = $1
-RETURNING id, parent_job_id, title, description, payload, max_retries, retries, status, created_at, updated_at, timeout_seconds
+RETURNING id, parent_job_id, title, description, payload, max_retries, retries, status, created_at, updated_at, timeout_seconds, claimed_until, heartbeat_at, cancel_requested_at, run_at, schedule_id, retry_strategy, retry_base_delay_seconds, retry_max_delay_seconds, retry_jitter, retry_max_duration_seconds, first_attempt_at, chain_trigger, workflow_id, step_name, result, payload_template, unique_key, priority, queue, rate_limit_key, throttles, concurrency_key, concurrency_limit, slot_waits
 `
 
 func (q *Queries) ReplayJob(ctx context.Context, id int32) (Job, error) {
@@ -263,28 +1080,339 @@ func (q *Queries) ReplayJob(ctx context.Context, id int32) (Job, error) {
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.TimeoutSeconds,
+		&i.ClaimedUntil,
+		&i.HeartbeatAt,
+		&i.CancelRequestedAt,
+		&i.RunAt,
+		&i.ScheduleID,
+		&i.RetryStrategy,
+		&i.RetryBaseDelaySeconds,
+		&i.RetryMaxDelaySeconds,
+		&i.RetryJitter,
+		&i.RetryMaxDurationSeconds,
+		&i.FirstAttemptAt,
+		&i.ChainTrigger,
+		&i.WorkflowID,
+		&i.StepName,
+		&i.Result,
+		&i.PayloadTemplate,
+		&i.UniqueKey,
+		&i.Priority,
+		&i.Queue,
+		&i.RateLimitKey,
+		&i.Throttles,
+		&i.ConcurrencyKey,
+		&i.ConcurrencyLimit,
+		&i.SlotWaits,
 	)
 	return i, err
 }
 
+const requestJobCancellation = `-- name: RequestJobCancellation :one
+UPDATE jobs
+SET 
+    cancel_requested_at = CURRENT_TIMESTAMP,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND status = 'in_progress'
+RETURNING id, parent_job_id, title, description, payload, max_retries, retries, status, created_at, updated_at, timeout_seconds, claimed_until, heartbeat_at, cancel_requested_at, run_at, schedule_id, retry_strategy, retry_base_delay_seconds, retry_max_delay_seconds, retry_jitter, retry_max_duration_seconds, first_attempt_at, chain_trigger, workflow_id, step_name, result, payload_template, unique_key, priority, queue, rate_limit_key, throttles, concurrency_key, concurrency_limit, slot_waits
+`
+
+func (q *Queries) RequestJobCancellation(ctx context.Context, id int32) (Job, error) {
+	row := q.db.QueryRow(ctx, requestJobCancellation, id)
+	var i Job
+	err := row.Scan(
+		&i.ID,
+		&i.ParentJobID,
+		&i.Title,
+		&i.Description,
+		&i.Payload,
+		&i.MaxRetries,
+		&i.Retries,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.TimeoutSeconds,
+		&i.ClaimedUntil,
+		&i.HeartbeatAt,
+		&i.CancelRequestedAt,
+		&i.RunAt,
+		&i.ScheduleID,
+		&i.RetryStrategy,
+		&i.RetryBaseDelaySeconds,
+		&i.RetryMaxDelaySeconds,
+		&i.RetryJitter,
+		&i.RetryMaxDurationSeconds,
+		&i.FirstAttemptAt,
+		&i.ChainTrigger,
+		&i.WorkflowID,
+		&i.StepName,
+		&i.Result,
+		&i.PayloadTemplate,
+		&i.UniqueKey,
+		&i.Priority,
+		&i.Queue,
+		&i.RateLimitKey,
+		&i.Throttles,
+		&i.ConcurrencyKey,
+		&i.ConcurrencyLimit,
+		&i.SlotWaits,
+	)
+	return i, err
+}
+
+const scheduleJobRetry = `-- name: ScheduleJobRetry :one
+UPDATE jobs
+SET 
+    status = 'failed',
+    retries = $2,
+    run_at = $3,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING id, parent_job_id, title, description, payload, max_retries, retries, status, created_at, updated_at, timeout_seconds, claimed_until, heartbeat_at, cancel_requested_at, run_at, schedule_id, retry_strategy, retry_base_delay_seconds, retry_max_delay_seconds, retry_jitter, retry_max_duration_seconds, first_attempt_at, chain_trigger, workflow_id, step_name, result, payload_template, unique_key, priority, queue, rate_limit_key, throttles, concurrency_key, concurrency_limit, slot_waits
+`
+
+type ScheduleJobRetryParams struct {
+	ID      int32
+	Retries pgtype.Int4
+	RunAt   pgtype.Timestamp
+}
+
+func (q *Queries) ScheduleJobRetry(ctx context.Context, arg ScheduleJobRetryParams) (Job, error) {
+	row := q.db.QueryRow(ctx, scheduleJobRetry, arg.ID, arg.Retries, arg.RunAt)
+	var i Job
+	err := row.Scan(
+		&i.ID,
+		&i.ParentJobID,
+		&i.Title,
+		&i.Description,
+		&i.Payload,
+		&i.MaxRetries,
+		&i.Retries,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.TimeoutSeconds,
+		&i.ClaimedUntil,
+		&i.HeartbeatAt,
+		&i.CancelRequestedAt,
+		&i.RunAt,
+		&i.ScheduleID,
+		&i.RetryStrategy,
+		&i.RetryBaseDelaySeconds,
+		&i.RetryMaxDelaySeconds,
+		&i.RetryJitter,
+		&i.RetryMaxDurationSeconds,
+		&i.FirstAttemptAt,
+		&i.ChainTrigger,
+		&i.WorkflowID,
+		&i.StepName,
+		&i.Result,
+		&i.PayloadTemplate,
+		&i.UniqueKey,
+		&i.Priority,
+		&i.Queue,
+		&i.RateLimitKey,
+		&i.Throttles,
+		&i.ConcurrencyKey,
+		&i.ConcurrencyLimit,
+		&i.SlotWaits,
+	)
+	return i, err
+}
+
+const skipChildJobs = `-- name: SkipChildJobs :many
+UPDATE jobs
+SET 
+    status = 'skipped',
+    updated_at = CURRENT_TIMESTAMP
+WHERE parent_job_id = $1 AND status = 'waiting'
+RETURNING id
+`
+
+func (q *Queries) SkipChildJobs(ctx context.Context, parentJobID pgtype.Int4) ([]int32, error) {
+	rows, err := q.db.Query(ctx, skipChildJobs, parentJobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const skipDependentJobs = `-- name: SkipDependentJobs :many
+UPDATE jobs
+SET 
+    status = 'skipped',
+    updated_at = CURRENT_TIMESTAMP
+WHERE status = 'waiting'
+  AND id IN (
+      SELECT job_id FROM job_dependencies
+      WHERE depends_on_job_id = $1
+  )
+RETURNING id
+`
+
+func (q *Queries) SkipDependentJobs(ctx context.Context, dependsOnJobID int32) ([]int32, error) {
+	rows, err := q.db.Query(ctx, skipDependentJobs, dependsOnJobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const startJob = `-- name: StartJob :one
+UPDATE jobs
+SET 
+    status = 'in_progress',
+    claimed_until = NULL,
+    throttles = 0,
+    slot_waits = 0,
+    heartbeat_at = CURRENT_TIMESTAMP,
+    first_attempt_at = COALESCE(first_attempt_at, CURRENT_TIMESTAMP),
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND status = 'pending'
+  AND NOT EXISTS (
+    SELECT 1 FROM pauses p
+    WHERE (p.scope = 'queue' AND p.name = jobs.queue)
+       OR (p.scope = 'job_type' AND p.name = COALESCE(NULLIF(upper(trim(jobs.payload->>'type')), ''), 'SHELL'))
+  )
+RETURNING id, parent_job_id, title, description, payload, max_retries, retries, status, created_at, updated_at, timeout_seconds, claimed_until, heartbeat_at, cancel_requested_at, run_at, schedule_id, retry_strategy, retry_base_delay_seconds, retry_max_delay_seconds, retry_jitter, retry_max_duration_seconds, first_attempt_at, chain_trigger, workflow_id, step_name, result, payload_template, unique_key, priority, queue, rate_limit_key, throttles, concurrency_key, concurrency_limit, slot_waits
+`
+
+func (q *Queries) StartJob(ctx context.Context, id int32) (Job, error) {
+	row := q.db.QueryRow(ctx, startJob, id)
+	var i Job
+	err := row.Scan(
+		&i.ID,
+		&i.ParentJobID,
+		&i.Title,
+		&i.Description,
+		&i.Payload,
+		&i.MaxRetries,
+		&i.Retries,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.TimeoutSeconds,
+		&i.ClaimedUntil,
+		&i.HeartbeatAt,
+		&i.CancelRequestedAt,
+		&i.RunAt,
+		&i.ScheduleID,
+		&i.RetryStrategy,
+		&i.RetryBaseDelaySeconds,
+		&i.RetryMaxDelaySeconds,
+		&i.RetryJitter,
+		&i.RetryMaxDurationSeconds,
+		&i.FirstAttemptAt,
+		&i.ChainTrigger,
+		&i.WorkflowID,
+		&i.StepName,
+		&i.Result,
+		&i.PayloadTemplate,
+		&i.UniqueKey,
+		&i.Priority,
+		&i.Queue,
+		&i.RateLimitKey,
+		&i.Throttles,
+		&i.ConcurrencyKey,
+		&i.ConcurrencyLimit,
+		&i.SlotWaits,
+	)
+	return i, err
+}
+
+const throttleJob = `-- name: ThrottleJob :execrows
+UPDATE jobs
+SET 
+    status = 'scheduled',
+    run_at = $2,
+    claimed_until = NULL,
+    throttles = throttles + 1,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND status = 'pending'
+`
+
+type ThrottleJobParams struct {
+	ID    int32
+	RunAt pgtype.Timestamp
+}
+
+func (q *Queries) ThrottleJob(ctx context.Context, arg ThrottleJobParams) (int64, error) {
+	result, err := q.db.Exec(ctx, throttleJob, arg.ID, arg.RunAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateJobPayload = `-- name: UpdateJobPayload :exec
+UPDATE jobs
+SET 
+    payload = $2,
+    payload_template = $3,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+`
+
+type UpdateJobPayloadParams struct {
+	ID              int32
+	Payload         []byte
+	PayloadTemplate []byte
+}
+
+func (q *Queries) UpdateJobPayload(ctx context.Context, arg UpdateJobPayloadParams) error {
+	_, err := q.db.Exec(ctx, updateJobPayload, arg.ID, arg.Payload, arg.PayloadTemplate)
+	return err
+}
+
 const updateJobStatus = `-- name: UpdateJobStatus :one
 UPDATE jobs
 SET 
     status = $2,
     retries = $3,
+    result = $4,
     updated_at = CURRENT_TIMESTAMP
 WHERE id = $1
-RETURNING id, parent_job_id, title, description, payload, max_retries, retries, status, created_at, updated_at, timeout_seconds
+RETURNING id, parent_job_id, title, description, payload, max_retries, retries, status, created_at, updated_at, timeout_seconds, claimed_until, heartbeat_at, cancel_requested_at, run_at, schedule_id, retry_strategy, retry_base_delay_seconds, retry_max_delay_seconds, retry_jitter, retry_max_duration_seconds, first_attempt_at, chain_trigger, workflow_id, step_name, result, payload_template, unique_key, priority, queue, rate_limit_key, throttles, concurrency_key, concurrency_limit, slot_waits
 `
 
 type UpdateJobStatusParams struct {
 	ID      int32
 	Status  NullJobStatus
 	Retries pgtype.Int4
+	Result  []byte
 }
 
 func (q *Queries) UpdateJobStatus(ctx context.Context, arg UpdateJobStatusParams) (Job, error) {
-	row := q.db.QueryRow(ctx, updateJobStatus, arg.ID, arg.Status, arg.Retries)
+	row := q.db.QueryRow(ctx, updateJobStatus,
+		arg.ID,
+		arg.Status,
+		arg.Retries,
+		arg.Result,
+	)
 	var i Job
 	err := row.Scan(
 		&i.ID,
@@ -298,6 +1426,30 @@ func (q *Queries) UpdateJobStatus(ctx context.Context, arg UpdateJobStatusParams
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.TimeoutSeconds,
+		&i.ClaimedUntil,
+		&i.HeartbeatAt,
+		&i.CancelRequestedAt,
+		&i.RunAt,
+		&i.ScheduleID,
+		&i.RetryStrategy,
+		&i.RetryBaseDelaySeconds,
+		&i.RetryMaxDelaySeconds,
+		&i.RetryJitter,
+		&i.RetryMaxDurationSeconds,
+		&i.FirstAttemptAt,
+		&i.ChainTrigger,
+		&i.WorkflowID,
+		&i.StepName,
+		&i.Result,
+		&i.PayloadTemplate,
+		&i.UniqueKey,
+		&i.Priority,
+		&i.Queue,
+		&i.RateLimitKey,
+		&i.Throttles,
+		&i.ConcurrencyKey,
+		&i.ConcurrencyLimit,
+		&i.SlotWaits,
 	)
 	return i, err
 }
@@ -11,6 +11,92 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type ChainTrigger string
+
+const (
+	ChainTriggerOnSuccess  ChainTrigger = "on_success"
+	ChainTriggerOnFailure  ChainTrigger = "on_failure"
+	ChainTriggerOnComplete ChainTrigger = "on_complete"
+)
+
+func (e *ChainTrigger) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ChainTrigger(s)
+	case string:
+		*e = ChainTrigger(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ChainTrigger: %T", src)
+	}
+	return nil
+}
+
+type NullChainTrigger struct {
+	ChainTrigger ChainTrigger
+	Valid        bool // Valid is true if ChainTrigger is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullChainTrigger) Scan(value interface{}) error {
+	if value == nil {
+		ns.ChainTrigger, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ChainTrigger.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullChainTrigger) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ChainTrigger), nil
+}
+
+type JobPriority string
+
+const (
+	JobPriorityLow    JobPriority = "low"
+	JobPriorityNormal JobPriority = "normal"
+	JobPriorityHigh   JobPriority = "high"
+)
+
+func (e *JobPriority) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = JobPriority(s)
+	case string:
+		*e = JobPriority(s)
+	default:
+		return fmt.Errorf("unsupported scan type for JobPriority: %T", src)
+	}
+	return nil
+}
+
+type NullJobPriority struct {
+	JobPriority JobPriority
+	Valid       bool // Valid is true if JobPriority is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullJobPriority) Scan(value interface{}) error {
+	if value == nil {
+		ns.JobPriority, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.JobPriority.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullJobPriority) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.JobPriority), nil
+}
+
 type JobStatus string
 
 const (
@@ -19,6 +105,10 @@ const (
 	JobStatusCompleted  JobStatus = "completed"
 	JobStatusFailed     JobStatus = "failed"
 	JobStatusDead       JobStatus = "dead"
+	JobStatusCancelled  JobStatus = "cancelled"
+	JobStatusScheduled  JobStatus = "scheduled"
+	JobStatusWaiting    JobStatus = "waiting"
+	JobStatusSkipped    JobStatus = "skipped"
 )
 
 func (e *JobStatus) Scan(src interface{}) error {
@@ -100,27 +190,344 @@ func (ns NullLogLevel) Value() (driver.Value, error) {
 	return string(ns.LogLevel), nil
 }
 
+type OverlapPolicy string
+
+const (
+	OverlapPolicySkip  OverlapPolicy = "skip"
+	OverlapPolicyQueue OverlapPolicy = "queue"
+	OverlapPolicyAllow OverlapPolicy = "allow"
+)
+
+func (e *OverlapPolicy) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = OverlapPolicy(s)
+	case string:
+		*e = OverlapPolicy(s)
+	default:
+		return fmt.Errorf("unsupported scan type for OverlapPolicy: %T", src)
+	}
+	return nil
+}
+
+type NullOverlapPolicy struct {
+	OverlapPolicy OverlapPolicy
+	Valid         bool // Valid is true if OverlapPolicy is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullOverlapPolicy) Scan(value interface{}) error {
+	if value == nil {
+		ns.OverlapPolicy, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.OverlapPolicy.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullOverlapPolicy) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.OverlapPolicy), nil
+}
+
+type PauseScope string
+
+const (
+	PauseScopeQueue   PauseScope = "queue"
+	PauseScopeJobType PauseScope = "job_type"
+)
+
+func (e *PauseScope) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = PauseScope(s)
+	case string:
+		*e = PauseScope(s)
+	default:
+		return fmt.Errorf("unsupported scan type for PauseScope: %T", src)
+	}
+	return nil
+}
+
+type NullPauseScope struct {
+	PauseScope PauseScope
+	Valid      bool // Valid is true if PauseScope is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullPauseScope) Scan(value interface{}) error {
+	if value == nil {
+		ns.PauseScope, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.PauseScope.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullPauseScope) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.PauseScope), nil
+}
+
+type RateLimitScope string
+
+const (
+	RateLimitScopeQueue   RateLimitScope = "queue"
+	RateLimitScopeJobType RateLimitScope = "job_type"
+	RateLimitScopeKey     RateLimitScope = "key"
+)
+
+func (e *RateLimitScope) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = RateLimitScope(s)
+	case string:
+		*e = RateLimitScope(s)
+	default:
+		return fmt.Errorf("unsupported scan type for RateLimitScope: %T", src)
+	}
+	return nil
+}
+
+type NullRateLimitScope struct {
+	RateLimitScope RateLimitScope
+	Valid          bool // Valid is true if RateLimitScope is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullRateLimitScope) Scan(value interface{}) error {
+	if value == nil {
+		ns.RateLimitScope, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.RateLimitScope.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullRateLimitScope) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.RateLimitScope), nil
+}
+
+type RetryStrategy string
+
+const (
+	RetryStrategyFixed       RetryStrategy = "fixed"
+	RetryStrategyLinear      RetryStrategy = "linear"
+	RetryStrategyExponential RetryStrategy = "exponential"
+)
+
+func (e *RetryStrategy) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = RetryStrategy(s)
+	case string:
+		*e = RetryStrategy(s)
+	default:
+		return fmt.Errorf("unsupported scan type for RetryStrategy: %T", src)
+	}
+	return nil
+}
+
+type NullRetryStrategy struct {
+	RetryStrategy RetryStrategy
+	Valid         bool // Valid is true if RetryStrategy is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullRetryStrategy) Scan(value interface{}) error {
+	if value == nil {
+		ns.RetryStrategy, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.RetryStrategy.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullRetryStrategy) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.RetryStrategy), nil
+}
+
+type WorkflowStatus string
+
+const (
+	WorkflowStatusRunning   WorkflowStatus = "running"
+	WorkflowStatusSucceeded WorkflowStatus = "succeeded"
+	WorkflowStatusFailed    WorkflowStatus = "failed"
+)
+
+func (e *WorkflowStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = WorkflowStatus(s)
+	case string:
+		*e = WorkflowStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for WorkflowStatus: %T", src)
+	}
+	return nil
+}
+
+type NullWorkflowStatus struct {
+	WorkflowStatus WorkflowStatus
+	Valid          bool // Valid is true if WorkflowStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullWorkflowStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.WorkflowStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.WorkflowStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullWorkflowStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.WorkflowStatus), nil
+}
+
+type IdempotencyKey struct {
+	Queue       string
+	Key         string
+	JobID       pgtype.Int4
+	RequestHash string
+	CreatedAt   pgtype.Timestamp
+	ExpiresAt   pgtype.Timestamp
+}
+
 type Job struct {
+	ID                      int32
+	ParentJobID             pgtype.Int4
+	Title                   string
+	Description             pgtype.Text
+	Payload                 []byte
+	MaxRetries              pgtype.Int4
+	Retries                 pgtype.Int4
+	Status                  NullJobStatus
+	CreatedAt               pgtype.Timestamp
+	UpdatedAt               pgtype.Timestamp
+	TimeoutSeconds          pgtype.Int4
+	ClaimedUntil            pgtype.Timestamp
+	HeartbeatAt             pgtype.Timestamp
+	CancelRequestedAt       pgtype.Timestamp
+	RunAt                   pgtype.Timestamp
+	ScheduleID              pgtype.Int4
+	RetryStrategy           RetryStrategy
+	RetryBaseDelaySeconds   int32
+	RetryMaxDelaySeconds    pgtype.Int4
+	RetryJitter             bool
+	RetryMaxDurationSeconds pgtype.Int4
+	FirstAttemptAt          pgtype.Timestamp
+	ChainTrigger            NullChainTrigger
+	WorkflowID              pgtype.Int4
+	StepName                pgtype.Text
+	Result                  []byte
+	PayloadTemplate         []byte
+	UniqueKey               pgtype.Text
+	Priority                JobPriority
+	Queue                   string
+	RateLimitKey            pgtype.Text
+	Throttles               int32
+	ConcurrencyKey          pgtype.Text
+	ConcurrencyLimit        pgtype.Int4
+	SlotWaits               int32
+}
+
+type JobAttempt struct {
+	ID         int32
+	JobID      int32
+	Attempt    int32
+	WorkerID   string
+	StartedAt  pgtype.Timestamp
+	FinishedAt pgtype.Timestamp
+	ExitCode   pgtype.Int4
+	Error      pgtype.Text
+	DurationMs pgtype.Int8
+}
+
+type JobDependency struct {
+	JobID          int32
+	DependsOnJobID int32
+}
+
+type JobLog struct {
+	ID         int32
+	JobID      int32
+	Stdout     pgtype.Text
+	Stderr     pgtype.Text
+	ExitCode   pgtype.Int4
+	CreatedAt  pgtype.Timestamp
+	Level      LogLevel
+	Message    string
+	StatusCode pgtype.Int4
+	Headers    []byte
+	AttemptID  pgtype.Int4
+}
+
+type OutboxMessage struct {
+	ID        int64
+	JobID     int32
+	CreatedAt pgtype.Timestamp
+	SentAt    pgtype.Timestamp
+}
+
+type Pause struct {
+	Scope    PauseScope
+	Name     string
+	PausedAt pgtype.Timestamp
+}
+
+type RateLimit struct {
+	Scope         RateLimitScope
+	Name          string
+	Rate          int32
+	PeriodSeconds int32
+	Burst         int32
+	CreatedAt     pgtype.Timestamp
+	UpdatedAt     pgtype.Timestamp
+}
+
+type Schedule struct {
 	ID             int32
-	ParentJobID    pgtype.Int4
+	Name           string
+	CronExpression string
+	Timezone       string
 	Title          string
 	Description    pgtype.Text
 	Payload        []byte
 	MaxRetries     pgtype.Int4
-	Retries        pgtype.Int4
-	Status         NullJobStatus
+	TimeoutSeconds pgtype.Int4
+	OverlapPolicy  OverlapPolicy
+	CatchUp        bool
+	Enabled        bool
+	NextRunAt      pgtype.Timestamp
+	LastRunAt      pgtype.Timestamp
 	CreatedAt      pgtype.Timestamp
 	UpdatedAt      pgtype.Timestamp
-	TimeoutSeconds pgtype.Int4
 }
 
-type JobLog struct {
+type Workflow struct {
 	ID        int32
-	JobID     int32
-	Stdout    pgtype.Text
-	Stderr    pgtype.Text
-	ExitCode  pgtype.Int4
+	Name      string
+	Status    WorkflowStatus
 	CreatedAt pgtype.Timestamp
-	Level     LogLevel
-	Message   string
+	UpdatedAt pgtype.Timestamp
 }
@@ -0,0 +1,105 @@
+package retry
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/tomiwa-a/Relay/internal/repository"
+)
+
+// DefaultBaseDelay gives the 2s, 4s, 8s... backoff jobs had before policies were configurable
+const DefaultBaseDelay = 2 * time.Second
+
+// Policy decides how long a failed job waits before its next attempt
+type Policy struct {
+	Strategy  repository.RetryStrategy
+	BaseDelay time.Duration
+	MaxDelay  time.Duration // 0 means uncapped
+	Jitter    bool
+	// MaxDuration limits how long after its first attempt a job may still be
+	// retried. 0 means no limit.
+	MaxDuration time.Duration
+}
+
+// Default returns the policy used when a job doesn't set one
+func Default() Policy {
+	return Policy{
+		Strategy:  repository.RetryStrategyExponential,
+		BaseDelay: DefaultBaseDelay,
+	}
+}
+
+// FromJob reads the policy stored on a job
+func FromJob(job repository.Job) Policy {
+	policy := Policy{
+		Strategy:  job.RetryStrategy,
+		BaseDelay: time.Duration(job.RetryBaseDelaySeconds) * time.Second,
+		Jitter:    job.RetryJitter,
+	}
+	if job.RetryMaxDelaySeconds.Valid {
+		policy.MaxDelay = time.Duration(job.RetryMaxDelaySeconds.Int32) * time.Second
+	}
+	if job.RetryMaxDurationSeconds.Valid {
+		policy.MaxDuration = time.Duration(job.RetryMaxDurationSeconds.Int32) * time.Second
+	}
+	return policy
+}
+
+// Validate reports the first problem with the policy, if any
+func (p Policy) Validate() error {
+	switch p.Strategy {
+	case repository.RetryStrategyFixed, repository.RetryStrategyLinear, repository.RetryStrategyExponential:
+	default:
+		return fmt.Errorf("unknown strategy %q, must be one of fixed, linear or exponential", p.Strategy)
+	}
+
+	if p.BaseDelay < 0 || p.MaxDelay < 0 || p.MaxDuration < 0 {
+		return errors.New("delays and durations must not be negative")
+	}
+	if p.MaxDelay > 0 && p.MaxDelay < p.BaseDelay {
+		return errors.New("max delay must not be shorter than the base delay")
+	}
+	return nil
+}
+
+// Delay returns how long to wait before the given retry (1 for the first retry)
+func (p Policy) Delay(retry int32) time.Duration {
+	var delay float64
+	switch p.Strategy {
+	case repository.RetryStrategyFixed:
+		delay = float64(p.BaseDelay)
+	case repository.RetryStrategyLinear:
+		delay = float64(p.BaseDelay) * float64(retry)
+	default:
+		delay = float64(p.BaseDelay) * math.Pow(2, float64(retry-1))
+	}
+
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+
+	// float64(math.MaxInt64) rounds up to 2^63, which doesn't fit in a Duration
+	d := time.Duration(math.MaxInt64)
+	if delay < math.MaxInt64 {
+		d = time.Duration(delay)
+	}
+	if p.Jitter && d > 1 {
+		// Equal jitter: keep half the delay and randomise the rest, so retries
+		// of jobs that failed together spread out without retrying early
+		d = d/2 + rand.N(d/2)
+	}
+	return d
+}
+
+// Expired reports whether a retry at the given time would fall outside the
+// policy's total retry window
+func (p Policy) Expired(firstAttempt pgtype.Timestamp, at time.Time) bool {
+	if p.MaxDuration <= 0 || !firstAttempt.Valid {
+		return false
+	}
+	return at.After(firstAttempt.Time.Add(p.MaxDuration))
+}
@@ -0,0 +1,152 @@
+package retry
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/tomiwa-a/Relay/internal/repository"
+)
+
+func TestDelay(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy Policy
+		retry  int32
+		want   time.Duration
+	}{
+		{name: "fixed first", policy: Policy{Strategy: repository.RetryStrategyFixed, BaseDelay: 5 * time.Second}, retry: 1, want: 5 * time.Second},
+		{name: "fixed later", policy: Policy{Strategy: repository.RetryStrategyFixed, BaseDelay: 5 * time.Second}, retry: 7, want: 5 * time.Second},
+		{name: "linear first", policy: Policy{Strategy: repository.RetryStrategyLinear, BaseDelay: 5 * time.Second}, retry: 1, want: 5 * time.Second},
+		{name: "linear third", policy: Policy{Strategy: repository.RetryStrategyLinear, BaseDelay: 5 * time.Second}, retry: 3, want: 15 * time.Second},
+		{name: "exponential first", policy: Default(), retry: 1, want: 2 * time.Second},
+		{name: "exponential fourth", policy: Default(), retry: 4, want: 16 * time.Second},
+		{
+			name:   "capped",
+			policy: Policy{Strategy: repository.RetryStrategyExponential, BaseDelay: time.Second, MaxDelay: 10 * time.Second},
+			retry:  5,
+			want:   10 * time.Second,
+		},
+		{
+			name:   "below the cap",
+			policy: Policy{Strategy: repository.RetryStrategyExponential, BaseDelay: time.Second, MaxDelay: 10 * time.Second},
+			retry:  4,
+			want:   8 * time.Second,
+		},
+		{name: "uncapped overflow", policy: Default(), retry: 100, want: time.Duration(math.MaxInt64)},
+		{name: "no base delay", policy: Policy{Strategy: repository.RetryStrategyExponential}, retry: 3, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Delay(tt.retry); got != tt.want {
+				t.Errorf("Delay(%d) = %v, want %v", tt.retry, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDelayJitter(t *testing.T) {
+	policy := Policy{Strategy: repository.RetryStrategyExponential, BaseDelay: time.Second, MaxDelay: time.Minute, Jitter: true}
+
+	tests := []struct {
+		retry int32
+		full  time.Duration
+	}{
+		{retry: 1, full: time.Second},
+		{retry: 3, full: 4 * time.Second},
+		{retry: 10, full: time.Minute},
+	}
+
+	for _, tt := range tests {
+		seen := make(map[time.Duration]bool)
+		for range 200 {
+			got := policy.Delay(tt.retry)
+			if got < tt.full/2 || got >= tt.full {
+				t.Fatalf("Delay(%d) = %v, want within [%v, %v)", tt.retry, got, tt.full/2, tt.full)
+			}
+			seen[got] = true
+		}
+		if len(seen) < 2 {
+			t.Errorf("Delay(%d) returned %v every time, want jittered delays", tt.retry, seen)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  Policy
+		wantErr bool
+	}{
+		{name: "default", policy: Default()},
+		{name: "fixed", policy: Policy{Strategy: repository.RetryStrategyFixed, BaseDelay: time.Second}},
+		{name: "capped", policy: Policy{Strategy: repository.RetryStrategyLinear, BaseDelay: time.Second, MaxDelay: time.Minute}},
+		{name: "cap equal to base", policy: Policy{Strategy: repository.RetryStrategyLinear, BaseDelay: time.Second, MaxDelay: time.Second}},
+		{name: "unknown strategy", policy: Policy{Strategy: "random", BaseDelay: time.Second}, wantErr: true},
+		{name: "empty strategy", policy: Policy{BaseDelay: time.Second}, wantErr: true},
+		{name: "negative base delay", policy: Policy{Strategy: repository.RetryStrategyFixed, BaseDelay: -time.Second}, wantErr: true},
+		{name: "negative max delay", policy: Policy{Strategy: repository.RetryStrategyFixed, MaxDelay: -time.Second}, wantErr: true},
+		{name: "negative max duration", policy: Policy{Strategy: repository.RetryStrategyFixed, MaxDuration: -time.Second}, wantErr: true},
+		{name: "cap below base", policy: Policy{Strategy: repository.RetryStrategyFixed, BaseDelay: time.Minute, MaxDelay: time.Second}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExpired(t *testing.T) {
+	first := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	started := pgtype.Timestamp{Time: first, Valid: true}
+
+	tests := []struct {
+		name         string
+		maxDuration  time.Duration
+		firstAttempt pgtype.Timestamp
+		at           time.Time
+		want         bool
+	}{
+		{name: "inside the window", maxDuration: time.Hour, firstAttempt: started, at: first.Add(59 * time.Minute)},
+		{name: "at the end of the window", maxDuration: time.Hour, firstAttempt: started, at: first.Add(time.Hour)},
+		{name: "after the window", maxDuration: time.Hour, firstAttempt: started, at: first.Add(61 * time.Minute), want: true},
+		{name: "no window", firstAttempt: started, at: first.Add(24 * time.Hour)},
+		{name: "never started", maxDuration: time.Hour, at: first.Add(24 * time.Hour)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := Policy{Strategy: repository.RetryStrategyFixed, MaxDuration: tt.maxDuration}
+			if got := policy.Expired(tt.firstAttempt, tt.at); got != tt.want {
+				t.Errorf("Expired = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromJob(t *testing.T) {
+	job := repository.Job{
+		RetryStrategy:           repository.RetryStrategyLinear,
+		RetryBaseDelaySeconds:   3,
+		RetryMaxDelaySeconds:    pgtype.Int4{Int32: 30, Valid: true},
+		RetryJitter:             true,
+		RetryMaxDurationSeconds: pgtype.Int4{Int32: 600, Valid: true},
+	}
+
+	want := Policy{
+		Strategy:    repository.RetryStrategyLinear,
+		BaseDelay:   3 * time.Second,
+		MaxDelay:    30 * time.Second,
+		Jitter:      true,
+		MaxDuration: 10 * time.Minute,
+	}
+	if got := FromJob(job); got != want {
+		t.Errorf("FromJob = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,232 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/robfig/cron/v3"
+	"github.com/tomiwa-a/Relay/internal/api/app"
+	"github.com/tomiwa-a/Relay/internal/queue"
+	"github.com/tomiwa-a/Relay/internal/repository"
+	"github.com/tomiwa-a/Relay/internal/retry"
+)
+
+const (
+	// leaderLockKey is the Postgres advisory lock held by the cron leader
+	leaderLockKey int64 = 0x72656c6179
+
+	// maxCatchUp caps how many missed ticks a schedule replays after downtime
+	maxCatchUp = 100
+)
+
+// NextRun returns the first tick of the cron expression after the given time,
+// evaluated in timezone. The result is in UTC.
+func NextRun(expression, timezone string, after time.Time) (time.Time, error) {
+	schedule, err := cron.ParseStandard(expression)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid cron expression: %w", err)
+	}
+
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timezone: %w", err)
+	}
+
+	next := schedule.Next(after.In(loc))
+	if next.IsZero() {
+		return time.Time{}, fmt.Errorf("cron expression %q never fires", expression)
+	}
+	return next.UTC(), nil
+}
+
+// Cron creates jobs from recurring schedules.
+// Only one process fires schedules at a time: the leader holds a Postgres
+// advisory lock on a dedicated connection, and another process takes over as
+// soon as that connection goes away.
+type Cron struct {
+	app *app.Application
+}
+
+func NewCron(app *app.Application) *Cron {
+	return &Cron{app: app}
+}
+
+func (c *Cron) Start(ctx context.Context) {
+	c.app.Logger.Println("starting cron scheduler...")
+
+	ticker := time.NewTicker(c.app.Config.Scheduler.PollInterval)
+	defer ticker.Stop()
+
+	var leader *pgxpool.Conn
+	defer func() {
+		if leader != nil {
+			c.resign(leader)
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if leader == nil {
+				leader = c.tryLead(ctx)
+				if leader == nil {
+					continue
+				}
+				c.app.Logger.Println("elected cron leader")
+			}
+
+			if err := leader.Ping(ctx); err != nil {
+				if ctx.Err() == nil {
+					c.app.Logger.Printf("lost cron leadership: %v", err)
+				}
+				leader.Release()
+				leader = nil
+				continue
+			}
+
+			if err := c.fireDue(ctx); err != nil && ctx.Err() == nil {
+				c.app.Logger.Printf("error firing schedules: %v", err)
+			}
+		}
+	}
+}
+
+// tryLead returns the connection holding the leader lock, or nil if another
+// process is the leader
+func (c *Cron) tryLead(ctx context.Context) *pgxpool.Conn {
+	conn, err := c.app.DB.Acquire(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			c.app.Logger.Printf("error acquiring connection for cron leader election: %v", err)
+		}
+		return nil
+	}
+
+	ok, err := repository.New(conn).TryAdvisoryLock(ctx, leaderLockKey)
+	if err != nil || !ok {
+		if err != nil && ctx.Err() == nil {
+			c.app.Logger.Printf("error during cron leader election: %v", err)
+		}
+		conn.Release()
+		return nil
+	}
+	return conn
+}
+
+func (c *Cron) resign(conn *pgxpool.Conn) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := repository.New(conn).AdvisoryUnlock(ctx, leaderLockKey); err != nil {
+		c.app.Logger.Printf("error releasing cron leader lock: %v", err)
+	}
+	conn.Release()
+}
+
+// fireDue creates jobs for every schedule whose next run has passed
+func (c *Cron) fireDue(ctx context.Context) error {
+	now := time.Now().UTC()
+
+	return c.app.InTx(ctx, func(q *repository.Queries) error {
+		schedules, err := q.ListDueSchedules(ctx, pgtype.Timestamp{Time: now, Valid: true})
+		if err != nil {
+			return err
+		}
+
+		for _, schedule := range schedules {
+			if err := c.fire(ctx, q, schedule, now); err != nil {
+				return fmt.Errorf("schedule [%d]: %w", schedule.ID, err)
+			}
+		}
+		return nil
+	})
+}
+
+// fire creates the jobs for a due schedule and moves it to its next tick.
+// Without catch-up, any number of missed ticks collapse into a single run.
+func (c *Cron) fire(ctx context.Context, q *repository.Queries, schedule repository.Schedule, now time.Time) error {
+	ticks := []time.Time{schedule.NextRunAt.Time}
+	next, err := NextRun(schedule.CronExpression, schedule.Timezone, schedule.NextRunAt.Time)
+	if err != nil {
+		return err
+	}
+
+	if schedule.CatchUp {
+		for !next.After(now) && len(ticks) < maxCatchUp {
+			ticks = append(ticks, next)
+			if next, err = NextRun(schedule.CronExpression, schedule.Timezone, next); err != nil {
+				return err
+			}
+		}
+	}
+	if !next.After(now) {
+		if schedule.CatchUp {
+			c.app.Logger.Printf("schedule [%d] missed more than %d ticks, skipping the rest", schedule.ID, maxCatchUp)
+		}
+		if next, err = NextRun(schedule.CronExpression, schedule.Timezone, now); err != nil {
+			return err
+		}
+	}
+
+	for _, tick := range ticks {
+		if err := c.createJob(ctx, q, schedule, tick); err != nil {
+			return err
+		}
+	}
+
+	return q.AdvanceSchedule(ctx, repository.AdvanceScheduleParams{
+		ID:        schedule.ID,
+		NextRunAt: pgtype.Timestamp{Time: next, Valid: true},
+		LastRunAt: pgtype.Timestamp{Time: now, Valid: true},
+	})
+}
+
+// createJob adds one run of the schedule, applying its overlap policy against
+// runs that haven't finished yet
+func (c *Cron) createJob(ctx context.Context, q *repository.Queries, schedule repository.Schedule, tick time.Time) error {
+	scheduleID := pgtype.Int4{Int32: schedule.ID, Valid: true}
+
+	status := repository.JobStatusPending
+	if schedule.OverlapPolicy != repository.OverlapPolicyAllow {
+		active, err := q.HasActiveScheduleJob(ctx, scheduleID)
+		if err != nil {
+			return err
+		}
+
+		if active {
+			if schedule.OverlapPolicy == repository.OverlapPolicySkip {
+				c.app.Logger.Printf("schedule [%d] previous run still going, skipping tick %s", schedule.ID, tick.Format(time.RFC3339))
+				return nil
+			}
+			// Queued runs wait as scheduled until the earlier runs finish
+			status = repository.JobStatusScheduled
+		}
+	}
+
+	job, err := q.CreateJob(ctx, repository.CreateJobParams{
+		Title:          schedule.Title,
+		Description:    schedule.Description,
+		Payload:        schedule.Payload,
+		MaxRetries:     schedule.MaxRetries,
+		TimeoutSeconds: schedule.TimeoutSeconds,
+		Status:         repository.NullJobStatus{JobStatus: status, Valid: true},
+		RunAt:          pgtype.Timestamp{Time: tick, Valid: true},
+		ScheduleID:     scheduleID,
+		Priority:       repository.JobPriorityNormal,
+		Queue:          queue.DefaultQueue,
+		// Scheduled runs use the default retry policy
+		RetryStrategy:         retry.Default().Strategy,
+		RetryBaseDelaySeconds: int32(retry.DefaultBaseDelay / time.Second),
+	})
+	if err != nil || status == repository.JobStatusScheduled {
+		return err
+	}
+
+	_, err = q.CreateOutboxMessage(ctx, job.ID)
+	return err
+}
@@ -0,0 +1,76 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/tomiwa-a/Relay/internal/api/app"
+	"github.com/tomiwa-a/Relay/internal/repository"
+)
+
+const batchSize = 100
+
+// Scheduler moves jobs whose run_at has passed back to pending and queues them.
+// This covers both jobs created with a run_at or delay and failed jobs waiting
+// out their retry backoff. The due time lives on the job row, so nothing is
+// lost if the process restarts while a job is waiting.
+type Scheduler struct {
+	app *app.Application
+}
+
+func NewScheduler(app *app.Application) *Scheduler {
+	return &Scheduler{app: app}
+}
+
+func (s *Scheduler) Start(ctx context.Context) {
+	s.app.Logger.Println("starting scheduler...")
+
+	ticker := time.NewTicker(s.app.Config.Scheduler.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for {
+				n, err := s.enqueueDue(ctx)
+				if err != nil {
+					if ctx.Err() == nil {
+						s.app.Logger.Printf("error enqueueing due jobs: %v", err)
+					}
+					break
+				}
+				if n < batchSize {
+					break
+				}
+			}
+		}
+	}
+}
+
+// enqueueDue queues one batch of due jobs. The outbox messages are written in
+// the same transaction, and rows are locked with SKIP LOCKED so several
+// schedulers can run side by side.
+func (s *Scheduler) enqueueDue(ctx context.Context) (int, error) {
+	var n int
+	err := s.app.InTx(ctx, func(q *repository.Queries) error {
+		jobIDs, err := q.EnqueueDueJobs(ctx, repository.EnqueueDueJobsParams{
+			Now:       pgtype.Timestamp{Time: time.Now().UTC(), Valid: true},
+			BatchSize: batchSize,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, id := range jobIDs {
+			if _, err := q.CreateOutboxMessage(ctx, id); err != nil {
+				return err
+			}
+		}
+		n = len(jobIDs)
+		return nil
+	})
+	return n, err
+}
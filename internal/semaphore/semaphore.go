@@ -0,0 +1,79 @@
+package semaphore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// acquireScript drops expired holders from the sorted set of a semaphore and
+// adds the caller if a slot is free, scored by when its lease runs out.
+// A caller that already holds a slot keeps it. Returns 1 if the slot is held.
+// KEYS[1] is the semaphore, ARGV the holder, the limit and the lease in ms.
+var acquireScript = redis.NewScript(`
+local clock = redis.call('TIME')
+local now = tonumber(clock[1]) * 1000 + math.floor(tonumber(clock[2]) / 1000)
+local lease = tonumber(ARGV[3])
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now)
+if not redis.call('ZSCORE', KEYS[1], ARGV[1]) and redis.call('ZCARD', KEYS[1]) >= tonumber(ARGV[2]) then
+	return 0
+end
+
+redis.call('ZADD', KEYS[1], now + lease, ARGV[1])
+redis.call('PEXPIRE', KEYS[1], lease)
+return 1
+`)
+
+// renewScript extends the lease of a holder that still has its slot.
+// KEYS[1] is the semaphore, ARGV the holder and the lease in ms.
+var renewScript = redis.NewScript(`
+local clock = redis.call('TIME')
+local now = tonumber(clock[1]) * 1000 + math.floor(tonumber(clock[2]) / 1000)
+local lease = tonumber(ARGV[2])
+
+if redis.call('ZSCORE', KEYS[1], ARGV[1]) then
+	redis.call('ZADD', KEYS[1], now + lease, ARGV[1])
+	redis.call('PEXPIRE', KEYS[1], lease)
+end
+return 0
+`)
+
+// Semaphore lets at most a fixed number of holders, across all workers, hold a
+// slot at once. Slots are leased, so a holder that dies without releasing its slot
+// loses it once the lease runs out.
+type Semaphore struct {
+	redis *redis.Client
+	key   string
+	limit int32
+}
+
+func New(rdb *redis.Client, name string, limit int32) *Semaphore {
+	return &Semaphore{
+		redis: rdb,
+		key:   fmt.Sprintf("concurrency:%s", name),
+		limit: limit,
+	}
+}
+
+// Acquire takes a slot for holder for the length of the lease. It reports
+// false if every slot is taken.
+func (s *Semaphore) Acquire(ctx context.Context, holder string, lease time.Duration) (bool, error) {
+	held, err := acquireScript.Run(ctx, s.redis, []string{s.key}, holder, s.limit, lease.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return held == 1, nil
+}
+
+// Renew extends holder's lease, as long as it still has its slot
+func (s *Semaphore) Renew(ctx context.Context, holder string, lease time.Duration) error {
+	return renewScript.Run(ctx, s.redis, []string{s.key}, holder, lease.Milliseconds()).Err()
+}
+
+// Release frees holder's slot
+func (s *Semaphore) Release(ctx context.Context, holder string) error {
+	return s.redis.ZRem(ctx, s.key, holder).Err()
+}
@@ -0,0 +1,64 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/tomiwa-a/Relay/internal/api/app"
+)
+
+// errJobCancelled is the cancellation cause of a job stopped through the cancel API
+var errJobCancelled = errors.New("job cancelled")
+
+func (w *Worker) trackRunning(jobID int32, cancel context.CancelCauseFunc) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.running[jobID] = cancel
+}
+
+func (w *Worker) untrackRunning(jobID int32) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	delete(w.running, jobID)
+}
+
+// cancelRunning stops the execution of a job if this worker is running it.
+// Cancelling the context kills a shell command's process.
+func (w *Worker) cancelRunning(jobID int32) {
+	w.mu.Lock()
+	cancel, ok := w.running[jobID]
+	w.mu.Unlock()
+
+	if ok {
+		w.app.Logger.Printf("cancelling running job [%d]", jobID)
+		cancel(errJobCancelled)
+	}
+}
+
+// listenForCancellations receives cancel requests published by the API.
+// Every worker gets every request and acts on the jobs it is running.
+func (w *Worker) listenForCancellations(ctx context.Context) {
+	sub := w.app.Redis.Subscribe(ctx, app.JobCancelChannel)
+	defer sub.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-sub.Channel():
+			if !ok {
+				return
+			}
+
+			jobID, err := strconv.Atoi(msg.Payload)
+			if err != nil {
+				w.app.Logger.Printf("invalid job ID in cancel request: %s", msg.Payload)
+				continue
+			}
+			w.cancelRunning(int32(jobID))
+		}
+	}
+}
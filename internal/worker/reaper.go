@@ -0,0 +1,137 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/tomiwa-a/Relay/internal/api/app"
+	"github.com/tomiwa-a/Relay/internal/chain"
+	"github.com/tomiwa-a/Relay/internal/repository"
+)
+
+// Reaper recovers jobs left in_progress by a worker that died mid-execution.
+// A job is orphaned once its heartbeat is older than Reaper.StaleAfter and, when
+// Redis is in use, its lock has expired. Orphans are re-queued as a new attempt,
+// marked dead once they have used up max_retries, or marked cancelled if a
+// cancellation was requested.
+type Reaper struct {
+	app *app.Application
+}
+
+func NewReaper(app *app.Application) *Reaper {
+	return &Reaper{app: app}
+}
+
+func (r *Reaper) Start(ctx context.Context) {
+	r.app.Logger.Println("starting orphaned job reaper...")
+
+	ticker := time.NewTicker(r.app.Config.Reaper.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.reap(ctx); err != nil && ctx.Err() == nil {
+				r.app.Logger.Printf("error reaping orphaned jobs: %v", err)
+			}
+		}
+	}
+}
+
+func (r *Reaper) reap(ctx context.Context) error {
+	// The cutoff is taken from the database clock, which also sets heartbeat_at
+	staleAfter := r.app.Config.Reaper.StaleAfter.Seconds()
+
+	jobs, err := r.app.Repository.ListStaleJobs(ctx, staleAfter)
+	if err != nil {
+		return err
+	}
+
+	for _, job := range jobs {
+		if r.app.Redis != nil {
+			held, err := r.app.Redis.Exists(ctx, fmt.Sprintf("job:lock:%d", job.ID)).Result()
+			if err != nil {
+				return err
+			}
+			if held > 0 {
+				continue
+			}
+		}
+
+		if err := r.reapJob(ctx, job, staleAfter); err != nil {
+			r.app.Logger.Printf("error reaping job [%d]: %v", job.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// reapJob moves an orphaned job on. The update re-checks the heartbeat, so a job
+// that came back to life, or was reaped by another process, is left alone.
+func (r *Reaper) reapJob(ctx context.Context, job repository.Job, staleAfter float64) error {
+	status := repository.JobStatusPending
+	retries := pgtype.Int4{Int32: job.Retries.Int32 + 1, Valid: true}
+	level := repository.LogLevelWARN
+	message := fmt.Sprintf("worker stopped responding, re-queueing job (attempt %d/%d)", retries.Int32, job.MaxRetries.Int32)
+
+	if job.CancelRequestedAt.Valid {
+		status = repository.JobStatusCancelled
+		retries = job.Retries
+		message = "worker stopped responding after the job was cancelled, marking as cancelled"
+	} else if job.Retries.Int32 >= job.MaxRetries.Int32 {
+		status = repository.JobStatusDead
+		retries = job.Retries
+		level = repository.LogLevelERROR
+		message = fmt.Sprintf("worker stopped responding and job has reached max retries (%d), marking as dead", job.MaxRetries.Int32)
+	}
+
+	err := r.app.InTx(ctx, func(q *repository.Queries) error {
+		_, err := q.ReapJob(ctx, repository.ReapJobParams{
+			Status:            repository.NullJobStatus{JobStatus: status, Valid: true},
+			Retries:           retries,
+			ID:                job.ID,
+			StaleAfterSeconds: staleAfter,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = q.AbandonJobAttempts(ctx, repository.AbandonJobAttemptsParams{
+			JobID: job.ID,
+			Error: pgtype.Text{String: "worker stopped responding", Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = q.CreateJobLog(ctx, repository.CreateJobLogParams{
+			JobID:   job.ID,
+			Level:   level,
+			Message: message,
+		})
+		if err != nil {
+			return err
+		}
+
+		if status == repository.JobStatusPending {
+			_, err = q.CreateOutboxMessage(ctx, job.ID)
+			return err
+		}
+		return chain.Settle(ctx, q, job, status)
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	r.app.Logger.Printf("[Job %d] [%s] %s", job.ID, level, message)
+	return nil
+}
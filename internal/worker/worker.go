@@ -2,245 +2,900 @@ package worker
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"math"
-	"strconv"
+	"os"
+	"sync"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
-	"github.com/segmentio/kafka-go"
 	"github.com/tomiwa-a/Relay/internal/api/app"
+	"github.com/tomiwa-a/Relay/internal/chain"
 	"github.com/tomiwa-a/Relay/internal/executor"
+	"github.com/tomiwa-a/Relay/internal/queue"
+	"github.com/tomiwa-a/Relay/internal/ratelimit"
 	"github.com/tomiwa-a/Relay/internal/repository"
+	"github.com/tomiwa-a/Relay/internal/retry"
+	"github.com/tomiwa-a/Relay/internal/semaphore"
 )
 
+// Delays between attempts to store a job's status after a database error
+const (
+	settleRetryMin = time.Second
+	settleRetryMax = 30 * time.Second
+)
+
+// drainSettleTimeout is how long interrupted jobs get to store their outcome during shutdown
+const drainSettleTimeout = 10 * time.Second
+
+// pauseCheckInterval is how often a paused queue is checked for being resumed
+const pauseCheckInterval = time.Second
+
+// concurrencyBackoff spaces out the tries of a job waiting for a slot of its
+// concurrency key: around 5s, 10s, 20s... up to a minute between tries, with
+// jitter so jobs that were turned away together don't all come back at once
+var concurrencyBackoff = retry.Policy{
+	Strategy:  repository.RetryStrategyExponential,
+	BaseDelay: 5 * time.Second,
+	MaxDelay:  time.Minute,
+	Jitter:    true,
+}
+
+// Worker consumes jobs from the queue and executes them.
+//
+// Delivery is at-least-once. A queue message is committed only after the job's
+// outcome (completed, dead, or failed with a retry scheduled) is stored in
+// Postgres, so a worker that crashes mid-job leaves the message uncommitted
+// and it is delivered again. Redelivered messages for jobs that are no longer
+// pending are skipped, so a job never starts twice from the same message;
+// a job left in_progress by a crashed worker is recovered by the Reaper.
+// A retry waiting out its backoff is held in memory by the worker that
+// scheduled it until it is written back to the outbox.
 type Worker struct {
-	app         *app.Application
-	kafkaReader *kafka.Reader
-	executor    executor.Executor
+	app       *app.Application
+	consumers map[string]queue.Consumer // by queue name
+	id        string                    // recorded on the attempts this worker runs
+
+	mu      sync.Mutex
+	running map[int32]context.CancelCauseFunc // cancels the execution of each running job
 }
 
-func NewWorker(app *app.Application, reader *kafka.Reader) *Worker {
+// NewWorker returns a worker that runs the jobs of each queue in consumers,
+// with the concurrency set for the queue in Worker.Queues
+func NewWorker(app *app.Application, consumers map[string]queue.Consumer) *Worker {
+	id := app.Config.Worker.ID
+	if id == "" {
+		hostname, _ := os.Hostname()
+		id = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+
 	return &Worker{
-		app:         app,
-		kafkaReader: reader,
-		executor:    executor.NewExecutor(),
+		app:       app,
+		consumers: consumers,
+		id:        id,
+		running:   make(map[int32]context.CancelCauseFunc),
 	}
 }
 
-func (w *Worker) logJob(ctx context.Context, jobID int32, level repository.LogLevel, message string) {
+// logJob records a log line for a job, linked to the attempt it belongs to if attemptID is valid
+func (w *Worker) logJob(ctx context.Context, jobID int32, attemptID pgtype.Int4, level repository.LogLevel, message string) {
 	w.app.Logger.Printf("[Job %d] [%s] %s", jobID, level, message)
 
 	_, _ = w.app.Repository.CreateJobLog(ctx, repository.CreateJobLogParams{
-		JobID:    jobID,
-		Level:    level,
-		Message:  message,
-		Stdout:   pgtype.Text{Valid: false},
-		Stderr:   pgtype.Text{Valid: false},
-		ExitCode: pgtype.Int4{Valid: false},
+		JobID:     jobID,
+		Level:     level,
+		Message:   message,
+		Stdout:    pgtype.Text{Valid: false},
+		Stderr:    pgtype.Text{Valid: false},
+		ExitCode:  pgtype.Int4{Valid: false},
+		AttemptID: attemptID,
 	})
 }
 
+// Start consumes every queue until ctx is cancelled. Each queue has its own
+// fetch loop and its own slots, so a busy queue can't take the slots of another.
+// Once ctx is cancelled no new jobs are fetched and Start returns after the
+// running jobs of all queues have drained.
 func (w *Worker) Start(ctx context.Context) {
-	w.app.Logger.Println("starting background worker...")
+	w.app.Logger.Printf("starting background worker on %d queues...", len(w.consumers))
+
+	// Running jobs outlive ctx: jobsCtx interrupts their execution and
+	// settleCtx bounds storing their outcome, both only once draining gives up
+	jobsCtx, interruptJobs := context.WithCancel(context.WithoutCancel(ctx))
+	defer interruptJobs()
+	settleCtx, abandonJobs := context.WithCancel(context.WithoutCancel(ctx))
+	defer abandonJobs()
+
+	// Cancel requests keep arriving while the worker drains
+	if w.app.Redis != nil {
+		go w.listenForCancellations(settleCtx)
+	}
+
+	var running sync.WaitGroup
+	defer w.drain(&running, interruptJobs, abandonJobs)
+
+	var consuming sync.WaitGroup
+	for name, consumer := range w.consumers {
+		concurrency := max(w.app.Config.Worker.Queues[name], 1)
+		w.app.Logger.Printf("consuming queue %q with %d slots", name, concurrency)
+
+		consuming.Add(1)
+		go func() {
+			defer consuming.Done()
+			w.consume(ctx, jobsCtx, settleCtx, name, consumer, concurrency, &running)
+		}()
+	}
+	consuming.Wait()
+}
+
+// consume runs up to concurrency jobs from one queue at a time until ctx is
+// cancelled. Jobs are claimed one by one and then executed in their own slot.
+func (w *Worker) consume(ctx, jobsCtx, settleCtx context.Context, name string, consumer queue.Consumer, concurrency int, running *sync.WaitGroup) {
+	slots := make(chan struct{}, concurrency)
 
 	for {
-		m, err := w.kafkaReader.ReadMessage(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case slots <- struct{}{}:
+		}
+
+		if !w.waitWhilePaused(ctx, name) {
+			<-slots
+			return
+		}
+
+		msg, err := consumer.Fetch(ctx)
 		if err != nil {
+			<-slots
 			if ctx.Err() != nil {
 				return
 			}
-			w.app.Logger.Printf("error reading message from kafka: %v", err)
+			w.app.Logger.Printf("error reading from queue: %v", err)
+			continue
+		}
+
+		// The job stays pending and is queued again when its type is resumed
+		if w.typePaused(ctx, msg) {
+			w.commit(settleCtx, msg)
+			<-slots
+			continue
+		}
+
+		// Messages published before jobs for embedded workers got their own
+		// topics are sent on again instead of being run here
+		if w.remote(msg) {
+			if err := w.handOff(ctx, msg.JobID); err != nil {
+				<-slots
+				return
+			}
+			w.commit(settleCtx, msg)
+			<-slots
 			continue
 		}
 
-		jobID, err := strconv.Atoi(string(m.Value))
+		job, release, err := w.claimJob(ctx, settleCtx, msg.JobID)
 		if err != nil {
-			w.app.Logger.Printf("invalid job ID in kafka message: %s", string(m.Value))
+			<-slots
+			return
+		}
+
+		if job == nil {
+			w.commit(settleCtx, msg)
+			<-slots
 			continue
 		}
 
-		w.processJob(ctx, int32(jobID))
+		running.Add(1)
+		go func() {
+			defer running.Done()
+			defer func() { <-slots }()
+			defer release()
+
+			// An error means the outcome couldn't be stored before the worker
+			// gave up, so the message is left for redelivery
+			if err := w.runJob(settleCtx, jobsCtx, *job); err != nil {
+				w.app.Logger.Printf("%v, leaving message uncommitted", err)
+				return
+			}
+			w.commit(settleCtx, msg)
+		}()
 	}
 }
 
-func (w *Worker) processJob(ctx context.Context, jobID int32) {
-	lockKey := fmt.Sprintf("job:lock:%d", jobID)
-	val, err := w.app.Redis.SetNX(ctx, lockKey, "locked", w.app.Config.Redis.LockTTL).Result()
+// waitWhilePaused blocks while a queue is paused, leaving its messages on the
+// queue. It returns false if ctx is cancelled first.
+func (w *Worker) waitWhilePaused(ctx context.Context, name string) bool {
+	logged := false
+	for {
+		paused, err := w.app.Paused(ctx, repository.PauseScopeQueue, name)
+		if err != nil && ctx.Err() == nil {
+			// Claiming re-checks the pause in Postgres, so carrying on is safe
+			w.app.Logger.Printf("error checking whether queue %q is paused: %v", name, err)
+		}
+		if !paused {
+			if logged {
+				w.app.Logger.Printf("queue %q resumed", name)
+			}
+			return ctx.Err() == nil
+		}
+
+		if !logged {
+			w.app.Logger.Printf("queue %q is paused, waiting for it to be resumed", name)
+			logged = true
+		}
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(pauseCheckInterval):
+		}
+	}
+}
+
+// typePaused reports whether a message is for a job of a paused type. The
+// cached state is only a hint: a message is passed over only once Postgres
+// confirms the pause, so the resume that lifts it is sure to queue the job
+// again. Jobs let through are still held back by StartJob while paused.
+func (w *Worker) typePaused(ctx context.Context, msg queue.Message) bool {
+	if msg.Type == "" {
+		return false
+	}
+
+	cached, err := w.app.Paused(ctx, repository.PauseScopeJobType, msg.Type)
+	if err != nil || !cached {
+		if err != nil && ctx.Err() == nil {
+			w.app.Logger.Printf("error checking whether job type %q is paused: %v", msg.Type, err)
+		}
+		return false
+	}
+
+	paused, err := w.app.Repository.IsPaused(ctx, repository.IsPausedParams{Scope: repository.PauseScopeJobType, Name: msg.Type})
+	if err != nil {
+		if ctx.Err() == nil {
+			w.app.Logger.Printf("error checking whether job type %q is paused: %v", msg.Type, err)
+		}
+		return false
+	}
+	if !paused {
+		if err := w.app.CachePause(ctx, repository.PauseScopeJobType, msg.Type, false); err != nil {
+			w.app.Logger.Printf("error caching resume of %s %q: %v", repository.PauseScopeJobType, msg.Type, err)
+		}
+		return false
+	}
+
+	w.app.Logger.Printf("job [%d] has paused type %s, leaving it pending", msg.JobID, msg.Type)
+	return true
+}
+
+// remote reports whether a message is for a job run by embedded workers
+func (w *Worker) remote(msg queue.Message) bool {
+	if msg.Type == "" {
+		return false
+	}
+	e, err := w.app.Executors.Get(msg.Type)
 	if err != nil {
-		w.app.Logger.Printf("error acquiring lock for job [%d]: %v", jobID, err)
+		return false
+	}
+	_, ok := e.(*executor.RemoteExecutor)
+	return ok
+}
+
+// handOff queues a job for embedded workers again, leaving it pending. The
+// outbox publishes it to the handler topic, which only embedded workers read.
+// An error is returned only once ctx is cancelled.
+func (w *Worker) handOff(ctx context.Context, jobID int32) error {
+	err := w.settle(ctx, fmt.Sprintf("error handing job [%d] to embedded workers", jobID), func() error {
+		_, err := w.app.Repository.CreateOutboxMessage(ctx, jobID)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	w.app.Logger.Printf("job [%d] is run by embedded workers, handing it on", jobID)
+	return nil
+}
+
+// drain waits for running jobs once the fetch loop has stopped. Jobs still
+// running after Worker.DrainTimeout are interrupted and re-queued; if their
+// outcome can't be stored within drainSettleTimeout they are abandoned to the Reaper.
+func (w *Worker) drain(running *sync.WaitGroup, interrupt, abandon context.CancelFunc) {
+	finished := make(chan struct{})
+	go func() {
+		running.Wait()
+		close(finished)
+	}()
+
+	w.app.Logger.Printf("worker draining, waiting up to %v for running jobs...", w.app.Config.Worker.DrainTimeout)
+
+	select {
+	case <-finished:
+		w.app.Logger.Println("worker drained")
 		return
+	case <-time.After(w.app.Config.Worker.DrainTimeout):
 	}
 
-	if !val {
-		w.app.Logger.Printf("job [%d] is already being processed by another worker, skipping", jobID)
+	w.app.Logger.Println("drain timeout reached, interrupting running jobs")
+	interrupt()
+
+	select {
+	case <-finished:
+		w.app.Logger.Println("worker drained")
 		return
+	case <-time.After(drainSettleTimeout):
 	}
 
-	// Watchdog logic
-	if w.app.Config.Redis.UseWatchdog {
-		watchdogCtx, stopWatchdog := context.WithCancel(ctx)
-		defer stopWatchdog()
+	w.app.Logger.Println("could not store the outcome of every interrupted job, leaving them to the reaper")
+	abandon()
+	<-finished
+}
 
-		go func() {
-			ticker := time.NewTicker(w.app.Config.Redis.LockTTL / 2)
-			defer ticker.Stop()
-
-			for {
-				select {
-				case <-watchdogCtx.Done():
-					w.app.Redis.Del(ctx, lockKey)
-					return
-				case <-ticker.C:
-					w.app.Redis.Expire(ctx, lockKey, w.app.Config.Redis.LockTTL)
-				}
-			}
-		}()
-	} else {
-		defer w.app.Redis.Del(ctx, lockKey)
+func (w *Worker) commit(ctx context.Context, msg queue.Message) {
+	if err := msg.Commit(ctx); err != nil && ctx.Err() == nil {
+		w.app.Logger.Printf("error committing message for job [%d]: %v", msg.JobID, err)
+	}
+}
+
+// claimJob locks a job and moves it to in_progress. A nil job means it should
+// be skipped. Errors are retried until they clear, since moving on would let a
+// later commit acknowledge this message too; an error is returned only once
+// ctx is cancelled.
+func (w *Worker) claimJob(ctx, lockCtx context.Context, jobID int32) (*repository.Job, func(), error) {
+	for {
+		job, release, err := w.tryClaimJob(ctx, lockCtx, jobID)
+		if err == nil {
+			return job, release, nil
+		}
+		if ctx.Err() != nil {
+			return nil, nil, ctx.Err()
+		}
+
+		w.app.Logger.Printf("%v, retrying in %v", err, settleRetryMin)
+
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-time.After(settleRetryMin):
+		}
+	}
+}
+
+// tryClaimJob makes one attempt at claiming a job. The lock lives as long as
+// lockCtx, which must outlast the job's execution.
+func (w *Worker) tryClaimJob(ctx, lockCtx context.Context, jobID int32) (*repository.Job, func(), error) {
+	release, err := w.acquireLock(lockCtx, jobID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if release == nil {
+		return nil, nil, nil
 	}
 
 	job, err := w.app.Repository.GetJob(ctx, jobID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		release()
+		return nil, nil, fmt.Errorf("error loading job [%d]: %w", jobID, err)
+	}
+	if err != nil || job.Status.JobStatus != repository.JobStatusPending {
+		release()
+		w.app.Logger.Printf("job [%d] is not pending, skipping", jobID)
+		return nil, nil, nil
+	}
+
+	releaseSlot, err := w.acquireSlot(ctx, lockCtx, job)
+	if err != nil || releaseSlot == nil {
+		release()
+		return nil, nil, err
+	}
+	releaseAll := func() {
+		releaseSlot()
+		release()
+	}
+
+	refund, err := w.throttle(ctx, lockCtx, job)
+	if err != nil || refund == nil {
+		releaseAll()
+		return nil, nil, err
+	}
+
+	// Moving the job out of pending is atomic, so only one worker ever runs it
+	job, err = w.app.Repository.StartJob(ctx, jobID)
 	if err != nil {
-		w.app.Logger.Printf("error fetching job [%d]: %v", jobID, err)
-		return
+		refund()
+		releaseAll()
+		if errors.Is(err, pgx.ErrNoRows) {
+			w.app.Logger.Printf("job [%d] is not pending or is paused, skipping", jobID)
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("error updating job [%d] to in_progress: %w", jobID, err)
 	}
 
-	if job.Status.JobStatus != repository.JobStatusPending {
-		w.app.Logger.Printf("job [%d] is not pending, skipping (status: %s)", jobID, job.Status.JobStatus)
-		return
+	return &job, releaseAll, nil
+}
+
+// acquireSlot takes a slot of the job's concurrency key and keeps it for as
+// long as lockCtx while the job runs. A nil release func means the job was
+// deferred because every slot is taken; it tries again after a backoff that
+// grows with each wait for a slot, and only the first wait is logged. Slots are
+// leased for the lock TTL, so the slot of a worker that dies mid-job frees up
+// once its lease runs out. Concurrency keys need Redis, so without it they are
+// not enforced.
+func (w *Worker) acquireSlot(ctx, lockCtx context.Context, job repository.Job) (release func(), err error) {
+	if w.app.Redis == nil || !job.ConcurrencyKey.Valid {
+		return func() {}, nil
+	}
+	jobID := job.ID
+
+	limit := int32(1)
+	if job.ConcurrencyLimit.Valid {
+		limit = job.ConcurrencyLimit.Int32
+	}
+	sem := semaphore.New(w.app.Redis, job.ConcurrencyKey.String, limit)
+	holder := fmt.Sprint(jobID)
+	lease := w.app.Config.Redis.LockTTL
+
+	held, err := sem.Acquire(ctx, holder, lease)
+	if err != nil {
+		return nil, fmt.Errorf("error acquiring concurrency slot for job [%d]: %w", jobID, err)
+	}
+
+	if !held {
+		runAt := time.Now().UTC().Add(concurrencyBackoff.Delay(job.SlotWaits + 1))
+		deferred, err := w.app.Repository.DeferJob(ctx, repository.DeferJobParams{
+			ID:    jobID,
+			RunAt: pgtype.Timestamp{Time: runAt, Valid: true},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error deferring job [%d] waiting for a concurrency slot: %w", jobID, err)
+		}
+		if deferred > 0 && job.SlotWaits == 0 {
+			w.logJob(ctx, jobID, pgtype.Int4{}, repository.LogLevelINFO, fmt.Sprintf("concurrency limit of %q reached, waiting for a slot", job.ConcurrencyKey.String))
+		}
+		return nil, nil
 	}
 
-	w.logJob(ctx, job.ID, repository.LogLevelINFO, fmt.Sprintf("processing job: %s", job.Title))
+	if !w.app.Config.Redis.UseWatchdog {
+		return func() { sem.Release(lockCtx, holder) }, nil
+	}
 
-	_, err = w.app.Repository.UpdateJobStatus(ctx, repository.UpdateJobStatusParams{
-		ID:      job.ID,
-		Status:  repository.NullJobStatus{JobStatus: repository.JobStatusInProgress, Valid: true},
-		Retries: job.Retries,
+	renewCtx, stopRenewing := context.WithCancel(lockCtx)
+
+	go func() {
+		ticker := time.NewTicker(lease / 2)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-renewCtx.Done():
+				sem.Release(lockCtx, holder)
+				return
+			case <-ticker.C:
+				sem.Renew(lockCtx, holder, lease)
+			}
+		}
+	}()
+
+	return stopRenewing, nil
+}
+
+// throttle takes a token from each of the job's rate limits. The returned
+// refund func gives them back if the job doesn't start after all. A nil refund
+// func means a limit is used up and the job was deferred: it waits as scheduled
+// until a token is due, without starting an attempt or using a retry, and only
+// the first deferral is logged. Rate limits need Redis, so without it they are
+// not enforced.
+func (w *Worker) throttle(ctx, lockCtx context.Context, job repository.Job) (refund func(), err error) {
+	if w.app.Redis == nil {
+		return func() {}, nil
+	}
+	jobID := job.ID
+
+	limits, err := w.app.Repository.ListJobRateLimits(ctx, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("error loading rate limits for job [%d]: %w", jobID, err)
+	}
+
+	wait, err := ratelimit.Take(ctx, w.app.Redis, limits)
+	if err != nil {
+		return nil, fmt.Errorf("error checking rate limits for job [%d]: %w", jobID, err)
+	}
+	if wait == 0 {
+		return func() {
+			if err := ratelimit.Refund(lockCtx, w.app.Redis, limits); err != nil {
+				w.app.Logger.Printf("error refunding rate limit tokens of job [%d]: %v", jobID, err)
+			}
+		}, nil
+	}
+
+	runAt := time.Now().UTC().Add(wait)
+	deferred, err := w.app.Repository.ThrottleJob(ctx, repository.ThrottleJobParams{
+		ID:    jobID,
+		RunAt: pgtype.Timestamp{Time: runAt, Valid: true},
 	})
 	if err != nil {
-		w.app.Logger.Printf("error updating job [%d] to in_progress: %v", job.ID, err)
-		return
+		return nil, fmt.Errorf("error deferring rate limited job [%d]: %w", jobID, err)
+	}
+	// A job that is no longer pending is skipped like any other
+	if deferred > 0 && job.Throttles == 0 {
+		w.logJob(ctx, jobID, pgtype.Int4{}, repository.LogLevelINFO, fmt.Sprintf("rate limit reached, deferred until %s", runAt.Format(time.RFC3339Nano)))
+	}
+	return nil, nil
+}
+
+// acquireLock takes the Redis lock for a job and keeps it alive while the job runs.
+// A nil release func means another worker holds the lock. Without Redis the
+// database status transition alone guards the job.
+func (w *Worker) acquireLock(ctx context.Context, jobID int32) (release func(), err error) {
+	if w.app.Redis == nil {
+		return func() {}, nil
 	}
 
+	lockKey := fmt.Sprintf("job:lock:%d", jobID)
+	val, err := w.app.Redis.SetNX(ctx, lockKey, "locked", w.app.Config.Redis.LockTTL).Result()
+	if err != nil {
+		return nil, fmt.Errorf("error acquiring lock for job [%d]: %w", jobID, err)
+	}
+
+	if !val {
+		w.app.Logger.Printf("job [%d] is already being processed by another worker, skipping", jobID)
+		return nil, nil
+	}
+
+	if !w.app.Config.Redis.UseWatchdog {
+		return func() { w.app.Redis.Del(ctx, lockKey) }, nil
+	}
+
+	// Watchdog logic
+	watchdogCtx, stopWatchdog := context.WithCancel(ctx)
+
+	go func() {
+		ticker := time.NewTicker(w.app.Config.Redis.LockTTL / 2)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-watchdogCtx.Done():
+				w.app.Redis.Del(ctx, lockKey)
+				return
+			case <-ticker.C:
+				w.app.Redis.Expire(ctx, lockKey, w.app.Config.Redis.LockTTL)
+			}
+		}
+	}()
+
+	return stopWatchdog, nil
+}
+
+// heartbeat marks the job as alive until stop is called, so the reaper
+// can tell it apart from a job orphaned by a dead worker. It also checks
+// whether the job has been cancelled.
+func (w *Worker) heartbeat(ctx context.Context, jobID int32) (stop func()) {
+	heartbeatCtx, stop := context.WithCancel(ctx)
+
+	go func() {
+		ticker := time.NewTicker(w.app.Config.Worker.HeartbeatInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-heartbeatCtx.Done():
+				return
+			case <-ticker.C:
+				cancelRequestedAt, err := w.app.Repository.HeartbeatJob(heartbeatCtx, jobID)
+				if err != nil {
+					if heartbeatCtx.Err() == nil && !errors.Is(err, pgx.ErrNoRows) {
+						w.app.Logger.Printf("error recording heartbeat for job [%d]: %v", jobID, err)
+					}
+					continue
+				}
+
+				// Picks up cancellations whose pub/sub message was missed
+				if cancelRequestedAt.Valid {
+					w.cancelRunning(jobID)
+				}
+			}
+		}
+	}()
+
+	return stop
+}
+
+// runJob executes a claimed job and records its outcome.
+// Execution stops when jobsCtx is cancelled; ctx is used to store the outcome.
+// A nil error means the message can be committed: the job's new status is
+// stored. An error means the message must not be committed.
+func (w *Worker) runJob(ctx, jobsCtx context.Context, job repository.Job) error {
+	jobExecutor, err := w.app.Executors.Resolve(job.Payload)
+	if err != nil {
+		w.logJob(ctx, job.ID, pgtype.Int4{}, repository.LogLevelERROR, fmt.Sprintf("cannot execute job: %v, marking as dead", err))
+		return w.setStatus(ctx, job, repository.JobStatusDead, job.Retries)
+	}
+
+	attemptID := w.startAttempt(ctx, job)
+	w.logJob(ctx, job.ID, attemptID, repository.LogLevelINFO, fmt.Sprintf("processing job: %s", job.Title))
+
 	// Job Execution with Timeout
 	execTimeout := 30 * time.Second
 	if job.TimeoutSeconds.Valid && job.TimeoutSeconds.Int32 > 0 {
 		execTimeout = time.Duration(job.TimeoutSeconds.Int32) * time.Second
 	}
 
-	execCtx, cancelExec := context.WithTimeout(ctx, execTimeout)
+	jobCtx, cancelJob := context.WithCancelCause(jobsCtx)
+	defer cancelJob(nil)
+
+	w.trackRunning(job.ID, cancelJob)
+	defer w.untrackRunning(job.ID)
+
+	execCtx, cancelExec := context.WithTimeout(jobCtx, execTimeout)
 	defer cancelExec()
 
+	stopHeartbeat := w.heartbeat(ctx, job.ID)
+	defer stopHeartbeat()
+
 	// Channel to capture execution result
-	done := make(chan error, 1)
+	done := make(chan execution, 1)
 
 	go func() {
-		result, err := w.executor.Execute(execCtx, job.Payload)
+		result, err := jobExecutor.Execute(execCtx, job.Payload)
 		if err != nil {
-			done <- err
+			run := execution{err: err}
+			if result != nil {
+				run.exitCode = pgtype.Int4{Int32: result.ExitCode, Valid: true}
+			}
+			done <- run
 			return
 		}
+		run := execution{exitCode: pgtype.Int4{Int32: result.ExitCode, Valid: true}}
 
 		// Log the execution result
-		w.logJob(execCtx, job.ID, repository.LogLevelINFO, fmt.Sprintf("job execution completed with exit code: %d", result.ExitCode))
+		w.logJob(execCtx, job.ID, attemptID, repository.LogLevelINFO, fmt.Sprintf("job execution completed with exit code: %d", result.ExitCode))
 
-		// Store stdout and stderr in logs
-		if result.Stdout != "" {
+		// Store the HTTP response, or stdout and stderr, in logs
+		if result.StatusCode != 0 {
+			headers, _ := json.Marshal(result.Headers)
+			_, _ = w.app.Repository.CreateJobLog(execCtx, repository.CreateJobLogParams{
+				JobID:      job.ID,
+				Level:      repository.LogLevelINFO,
+				Message:    fmt.Sprintf("response status: %d", result.StatusCode),
+				Stdout:     pgtype.Text{String: result.Stdout, Valid: true},
+				Stderr:     pgtype.Text{Valid: false},
+				ExitCode:   pgtype.Int4{Int32: result.ExitCode, Valid: true},
+				StatusCode: pgtype.Int4{Int32: result.StatusCode, Valid: true},
+				Headers:    headers,
+				AttemptID:  attemptID,
+			})
+		} else if result.Stdout != "" {
 			_, _ = w.app.Repository.CreateJobLog(execCtx, repository.CreateJobLogParams{
-				JobID:    job.ID,
-				Level:    repository.LogLevelINFO,
-				Message:  "stdout",
-				Stdout:   pgtype.Text{String: result.Stdout, Valid: true},
-				Stderr:   pgtype.Text{Valid: false},
-				ExitCode: pgtype.Int4{Int32: result.ExitCode, Valid: true},
+				JobID:     job.ID,
+				Level:     repository.LogLevelINFO,
+				Message:   "stdout",
+				Stdout:    pgtype.Text{String: result.Stdout, Valid: true},
+				Stderr:    pgtype.Text{Valid: false},
+				ExitCode:  pgtype.Int4{Int32: result.ExitCode, Valid: true},
+				AttemptID: attemptID,
 			})
 		}
 
 		if result.Stderr != "" {
 			_, _ = w.app.Repository.CreateJobLog(execCtx, repository.CreateJobLogParams{
-				JobID:    job.ID,
-				Level:    repository.LogLevelWARN,
-				Message:  "stderr",
-				Stdout:   pgtype.Text{Valid: false},
-				Stderr:   pgtype.Text{String: result.Stderr, Valid: true},
-				ExitCode: pgtype.Int4{Int32: result.ExitCode, Valid: true},
+				JobID:     job.ID,
+				Level:     repository.LogLevelWARN,
+				Message:   "stderr",
+				Stdout:    pgtype.Text{Valid: false},
+				Stderr:    pgtype.Text{String: result.Stderr, Valid: true},
+				ExitCode:  pgtype.Int4{Int32: result.ExitCode, Valid: true},
+				AttemptID: attemptID,
 			})
 		}
 
 		// If exit code is non-zero, treat as failure
-		if result.ExitCode != 0 {
-			done <- errors.New(fmt.Sprintf("command exited with code %d", result.ExitCode))
-			return
+		if result.ExitCode != 0 && result.Error != nil {
+			run.err = result.Error
+		} else if result.ExitCode != 0 {
+			run.err = &executor.ExitError{Code: result.ExitCode}
+		} else {
+			run.err = result.Error
+			run.result = result.Result
 		}
-
-		done <- result.Error
+		done <- run
 	}()
 
+	var run execution
 	select {
 	case <-execCtx.Done():
-		err = execCtx.Err()
-		if err == context.DeadlineExceeded {
-			err = fmt.Errorf("job execution timed out after %v", execTimeout)
+		run.err = context.Cause(execCtx)
+		if execCtx.Err() == context.DeadlineExceeded {
+			run.err = executor.Transient(fmt.Errorf("job execution timed out after %v", execTimeout))
 		}
-	case execErr := <-done:
-		err = execErr
+	case run = <-done:
+	}
+	err = run.err
+
+	w.finishAttempt(ctx, attemptID, run)
+
+	if err != nil && context.Cause(jobCtx) == errJobCancelled {
+		w.logJob(ctx, job.ID, attemptID, repository.LogLevelWARN, "job cancelled while running")
+		return w.setStatus(ctx, job, repository.JobStatusCancelled, job.Retries)
+	}
+
+	if err != nil && jobsCtx.Err() != nil {
+		return w.requeueInterrupted(ctx, job, attemptID)
+	}
+
+	if err != nil {
+		return w.handleFailure(ctx, job, attemptID, err)
+	}
+
+	job.Result = run.result
+	if err := w.setStatus(ctx, job, repository.JobStatusCompleted, job.Retries); err != nil {
+		return err
 	}
+	w.logJob(ctx, job.ID, attemptID, repository.LogLevelINFO, "job completed successfully")
+	return nil
+}
+
+// execution is what the executor goroutine reports back to runJob
+type execution struct {
+	err      error
+	exitCode pgtype.Int4
+	result   json.RawMessage
+}
 
+// startAttempt records the start of a job's next attempt. The returned ID is
+// invalid if the attempt couldn't be recorded; the job runs regardless.
+func (w *Worker) startAttempt(ctx context.Context, job repository.Job) pgtype.Int4 {
+	// Attempts are numbered from the ones already recorded, since runs that are
+	// interrupted or replayed don't move the retry count on
+	attempt, err := w.app.Repository.CreateJobAttempt(ctx, repository.CreateJobAttemptParams{
+		JobID:    job.ID,
+		WorkerID: w.id,
+	})
 	if err != nil {
-		w.handleFailure(ctx, job, err)
+		w.app.Logger.Printf("error recording attempt for job [%d]: %v", job.ID, err)
+		return pgtype.Int4{}
+	}
+	return pgtype.Int4{Int32: attempt.ID, Valid: true}
+}
+
+func (w *Worker) finishAttempt(ctx context.Context, attemptID pgtype.Int4, run execution) {
+	if !attemptID.Valid {
 		return
 	}
 
-	_, err = w.app.Repository.UpdateJobStatus(ctx, repository.UpdateJobStatusParams{
-		ID:      job.ID,
-		Status:  repository.NullJobStatus{JobStatus: repository.JobStatusCompleted, Valid: true},
-		Retries: job.Retries,
+	errText := pgtype.Text{}
+	if run.err != nil {
+		errText = pgtype.Text{String: run.err.Error(), Valid: true}
+	}
+
+	err := w.app.Repository.FinishJobAttempt(ctx, repository.FinishJobAttemptParams{
+		ID:       attemptID.Int32,
+		ExitCode: run.exitCode,
+		Error:    errText,
 	})
 	if err != nil {
-		w.app.Logger.Printf("error updating job [%d] to completed: %v", job.ID, err)
-	} else {
-		w.logJob(ctx, job.ID, repository.LogLevelINFO, "job completed successfully")
+		w.app.Logger.Printf("error recording the end of attempt [%d]: %v", attemptID.Int32, err)
 	}
 }
 
-func (w *Worker) handleFailure(ctx context.Context, job repository.Job, execErr error) {
-	w.app.Logger.Printf("job [%d] failed: %v", job.ID, execErr)
+func (w *Worker) handleFailure(ctx context.Context, job repository.Job, attemptID pgtype.Int4, execErr error) error {
+	if executor.IsTransient(execErr) {
+		w.app.Logger.Printf("job [%d] failed with a transient error: %v", job.ID, execErr)
+	} else {
+		w.app.Logger.Printf("job [%d] failed: %v", job.ID, execErr)
+	}
+
+	if executor.IsPermanent(execErr) {
+		w.logJob(ctx, job.ID, attemptID, repository.LogLevelERROR, fmt.Sprintf("job failed permanently: %v, marking as dead", execErr))
+		return w.setStatus(ctx, job, repository.JobStatusDead, job.Retries)
+	}
 
 	if job.Retries.Int32 < job.MaxRetries.Int32 {
 		nextRetry := job.Retries.Int32 + 1
-		backoff := time.Duration(math.Pow(2, float64(nextRetry))) * time.Second
+		policy := retry.FromJob(job)
+		backoff := policy.Delay(nextRetry)
+		nextAttempt := time.Now().UTC().Add(backoff)
+
+		if policy.Expired(job.FirstAttemptAt, nextAttempt) {
+			w.logJob(ctx, job.ID, attemptID, repository.LogLevelERROR, fmt.Sprintf("job would retry after its %v retry window, marking as dead", policy.MaxDuration))
+			return w.setStatus(ctx, job, repository.JobStatusDead, job.Retries)
+		}
 
-		w.app.Logger.Printf("retrying job [%d] in %v (attempt %d/%d)", job.ID, backoff, nextRetry, job.MaxRetries.Int32)
+		w.logJob(ctx, job.ID, attemptID, repository.LogLevelWARN, fmt.Sprintf("retrying in %v at %s (attempt %d/%d)", backoff, nextAttempt.Format(time.RFC3339), nextRetry, job.MaxRetries.Int32))
 
-		_, err := w.app.Repository.UpdateJobStatus(ctx, repository.UpdateJobStatusParams{
-			ID:      job.ID,
-			Status:  repository.NullJobStatus{JobStatus: repository.JobStatusPending, Valid: true},
-			Retries: pgtype.Int4{Int32: nextRetry, Valid: true},
+		// The job waits out the backoff as failed; the scheduler re-queues it once
+		// run_at passes, so a pending retry survives a restart
+		runAt := pgtype.Timestamp{Time: nextAttempt, Valid: true}
+		return w.settle(ctx, fmt.Sprintf("error scheduling retry for job [%d]", job.ID), func() error {
+			_, err := w.app.Repository.ScheduleJobRetry(ctx, repository.ScheduleJobRetryParams{
+				ID:      job.ID,
+				Retries: pgtype.Int4{Int32: nextRetry, Valid: true},
+				RunAt:   runAt,
+			})
+			return err
 		})
-		if err != nil {
-			w.app.Logger.Printf("error updating job [%d] for retry: %v", job.ID, err)
-			return
-		}
+	}
 
-		// Wait for backoff then re-push to Kafka to trigger again
-		go func() {
-			time.Sleep(backoff)
-			msg := kafka.Message{
-				Key:   []byte(strconv.Itoa(int(job.ID))),
-				Value: []byte(strconv.Itoa(int(job.ID))),
+	w.logJob(ctx, job.ID, attemptID, repository.LogLevelERROR, fmt.Sprintf("job has reached max retries (%d), marking as dead", job.MaxRetries.Int32))
+	return w.setStatus(ctx, job, repository.JobStatusDead, job.Retries) // DLQ: Marked as dead
+}
+
+// requeueInterrupted puts a job stopped by shutdown back in the queue.
+// The interrupted run doesn't count as an attempt.
+func (w *Worker) requeueInterrupted(ctx context.Context, job repository.Job, attemptID pgtype.Int4) error {
+	message := "worker shut down before the job finished, re-queueing"
+	w.app.Logger.Printf("[Job %d] [%s] %s", job.ID, repository.LogLevelWARN, message)
+
+	return w.settle(ctx, fmt.Sprintf("error re-queueing job [%d]", job.ID), func() error {
+		return w.app.InTx(ctx, func(q *repository.Queries) error {
+			_, err := q.UpdateJobStatus(ctx, repository.UpdateJobStatusParams{
+				ID:      job.ID,
+				Status:  repository.NullJobStatus{JobStatus: repository.JobStatusPending, Valid: true},
+				Retries: job.Retries,
+			})
+			if err != nil {
+				return err
 			}
-			if err := w.app.KafkaWriter.WriteMessages(ctx, msg); err != nil {
-				w.app.Logger.Printf("failed to re-push job [%d] to kafka: %v", job.ID, err)
+
+			_, err = q.CreateJobLog(ctx, repository.CreateJobLogParams{
+				JobID:     job.ID,
+				Level:     repository.LogLevelWARN,
+				Message:   message,
+				AttemptID: attemptID,
+			})
+			if err != nil {
+				return err
 			}
-		}()
-	} else {
-		w.logJob(ctx, job.ID, repository.LogLevelERROR, fmt.Sprintf("job has reached max retries (%d), marking as dead", job.MaxRetries.Int32))
-		_, _ = w.app.Repository.UpdateJobStatus(ctx, repository.UpdateJobStatusParams{
-			ID:      job.ID,
-			Status:  repository.NullJobStatus{JobStatus: repository.JobStatusDead, Valid: true}, // DLQ: Marked as dead
-			Retries: job.Retries,
+
+			_, err = q.CreateOutboxMessage(ctx, job.ID)
+			return err
+		})
+	})
+}
+
+// setStatus stores a job's new status, retrying until it succeeds or ctx is
+// cancelled so the queue message is never committed ahead of the database.
+func (w *Worker) setStatus(ctx context.Context, job repository.Job, status repository.JobStatus, retries pgtype.Int4) error {
+	return w.settle(ctx, fmt.Sprintf("error updating job [%d] to %s", job.ID, status), func() error {
+		// Chained children start in the same transaction the outcome is stored in
+		return w.app.InTx(ctx, func(q *repository.Queries) error {
+			_, err := q.UpdateJobStatus(ctx, repository.UpdateJobStatusParams{
+				ID:      job.ID,
+				Status:  repository.NullJobStatus{JobStatus: status, Valid: true},
+				Retries: retries,
+				Result:  job.Result,
+			})
+			if err != nil {
+				return err
+			}
+			return chain.Settle(ctx, q, job, status)
 		})
+	})
+}
+
+// settle runs fn until it succeeds, backing off between attempts, and gives up only once ctx is cancelled
+func (w *Worker) settle(ctx context.Context, what string, fn func() error) error {
+	delay := settleRetryMin
+
+	for {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return fmt.Errorf("%s: %w", what, ctx.Err())
+		}
+
+		w.app.Logger.Printf("%s, retrying in %v: %v", what, delay, err)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s: %w", what, ctx.Err())
+		case <-time.After(delay):
+		}
+
+		delay = min(delay*2, settleRetryMax)
 	}
 }
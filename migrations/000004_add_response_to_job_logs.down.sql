@@ -0,0 +1,3 @@
+ALTER TABLE job_logs
+DROP COLUMN status_code,
+DROP COLUMN headers;
@@ -0,0 +1,3 @@
+ALTER TABLE job_logs
+ADD COLUMN status_code INT,
+ADD COLUMN headers JSONB;
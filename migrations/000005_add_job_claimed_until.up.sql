@@ -0,0 +1,3 @@
+-- Set when a worker on the postgres queue backend claims a pending job, so
+-- other workers skip it until the worker starts it or the claim runs out
+ALTER TABLE jobs ADD COLUMN claimed_until TIMESTAMP;
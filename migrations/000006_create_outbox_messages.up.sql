@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS outbox_messages (
+	id BIGSERIAL PRIMARY KEY,
+	job_id INT NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	sent_at TIMESTAMP
+);
+
+CREATE INDEX idx_outbox_messages_unsent ON outbox_messages(id) WHERE sent_at IS NULL;
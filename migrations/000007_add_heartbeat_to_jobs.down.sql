@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_jobs_in_progress_heartbeat;
+
+ALTER TABLE jobs DROP COLUMN heartbeat_at;
@@ -0,0 +1,3 @@
+ALTER TABLE jobs ADD COLUMN heartbeat_at TIMESTAMP;
+
+CREATE INDEX idx_jobs_in_progress_heartbeat ON jobs(heartbeat_at) WHERE status = 'in_progress';
@@ -0,0 +1,3 @@
+ALTER TYPE job_status ADD VALUE IF NOT EXISTS 'cancelled';
+
+ALTER TABLE jobs ADD COLUMN cancel_requested_at TIMESTAMP;
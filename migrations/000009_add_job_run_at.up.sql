@@ -0,0 +1,5 @@
+ALTER TYPE job_status ADD VALUE IF NOT EXISTS 'scheduled';
+
+ALTER TABLE jobs ADD COLUMN run_at TIMESTAMP;
+
+CREATE INDEX idx_jobs_run_at ON jobs(run_at) WHERE run_at IS NOT NULL;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_jobs_schedule_id;
+
+ALTER TABLE jobs DROP COLUMN schedule_id;
+
+DROP TABLE IF EXISTS schedules;
+
+DROP TYPE IF EXISTS overlap_policy;
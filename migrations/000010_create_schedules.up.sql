@@ -0,0 +1,26 @@
+CREATE TYPE overlap_policy AS ENUM ('skip', 'queue', 'allow');
+
+CREATE TABLE IF NOT EXISTS schedules (
+	id SERIAL PRIMARY KEY,
+	name VARCHAR(255) NOT NULL UNIQUE,
+	cron_expression VARCHAR(255) NOT NULL,
+	timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+	title VARCHAR(255) NOT NULL,
+	description TEXT,
+	payload JSONB NOT NULL DEFAULT '{}',
+	max_retries INT DEFAULT 3,
+	timeout_seconds INT DEFAULT 30,
+	overlap_policy overlap_policy NOT NULL DEFAULT 'skip',
+	catch_up BOOLEAN NOT NULL DEFAULT FALSE,
+	enabled BOOLEAN NOT NULL DEFAULT TRUE,
+	next_run_at TIMESTAMP NOT NULL,
+	last_run_at TIMESTAMP,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_schedules_next_run_at ON schedules(next_run_at) WHERE enabled;
+
+ALTER TABLE jobs ADD COLUMN schedule_id INT REFERENCES schedules(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_jobs_schedule_id ON jobs(schedule_id);
@@ -0,0 +1,9 @@
+ALTER TABLE jobs
+DROP COLUMN first_attempt_at,
+DROP COLUMN retry_max_duration_seconds,
+DROP COLUMN retry_jitter,
+DROP COLUMN retry_max_delay_seconds,
+DROP COLUMN retry_base_delay_seconds,
+DROP COLUMN retry_strategy;
+
+DROP TYPE IF EXISTS retry_strategy;
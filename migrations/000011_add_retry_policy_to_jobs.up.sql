@@ -0,0 +1,9 @@
+CREATE TYPE retry_strategy AS ENUM ('fixed', 'linear', 'exponential');
+
+ALTER TABLE jobs
+ADD COLUMN retry_strategy retry_strategy NOT NULL DEFAULT 'exponential',
+ADD COLUMN retry_base_delay_seconds INT NOT NULL DEFAULT 2,
+ADD COLUMN retry_max_delay_seconds INT,
+ADD COLUMN retry_jitter BOOLEAN NOT NULL DEFAULT FALSE,
+ADD COLUMN retry_max_duration_seconds INT,
+ADD COLUMN first_attempt_at TIMESTAMP;
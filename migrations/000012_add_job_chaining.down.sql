@@ -0,0 +1,21 @@
+ALTER TABLE jobs DROP COLUMN chain_trigger;
+
+DROP TYPE IF EXISTS chain_trigger;
+
+UPDATE jobs SET status = 'cancelled' WHERE status IN ('waiting', 'skipped');
+
+DROP INDEX IF EXISTS idx_jobs_in_progress_heartbeat;
+DROP INDEX IF EXISTS idx_jobs_status;
+
+ALTER TYPE job_status RENAME TO job_status_old;
+CREATE TYPE job_status AS ENUM ('pending', 'in_progress', 'completed', 'failed', 'dead', 'cancelled', 'scheduled');
+
+ALTER TABLE jobs
+ALTER COLUMN status DROP DEFAULT,
+ALTER COLUMN status TYPE job_status USING status::text::job_status,
+ALTER COLUMN status SET DEFAULT 'pending';
+
+DROP TYPE job_status_old;
+
+CREATE INDEX idx_jobs_status ON jobs(status);
+CREATE INDEX idx_jobs_in_progress_heartbeat ON jobs(heartbeat_at) WHERE status = 'in_progress';
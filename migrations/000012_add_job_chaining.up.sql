@@ -0,0 +1,6 @@
+ALTER TYPE job_status ADD VALUE IF NOT EXISTS 'waiting';
+ALTER TYPE job_status ADD VALUE IF NOT EXISTS 'skipped';
+
+CREATE TYPE chain_trigger AS ENUM ('on_success', 'on_failure', 'on_complete');
+
+ALTER TABLE jobs ADD COLUMN chain_trigger chain_trigger;
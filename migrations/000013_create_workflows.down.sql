@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS job_dependencies;
+
+DROP INDEX IF EXISTS idx_jobs_workflow_step;
+DROP INDEX IF EXISTS idx_jobs_workflow_id;
+
+ALTER TABLE jobs
+DROP COLUMN step_name,
+DROP COLUMN workflow_id;
+
+DROP TABLE IF EXISTS workflows;
+
+DROP TYPE IF EXISTS workflow_status;
@@ -0,0 +1,24 @@
+CREATE TYPE workflow_status AS ENUM ('running', 'succeeded', 'failed');
+
+CREATE TABLE IF NOT EXISTS workflows (
+	id SERIAL PRIMARY KEY,
+	name VARCHAR(255) NOT NULL,
+	status workflow_status NOT NULL DEFAULT 'running',
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE jobs
+ADD COLUMN workflow_id INT REFERENCES workflows(id) ON DELETE CASCADE,
+ADD COLUMN step_name VARCHAR(255);
+
+CREATE INDEX idx_jobs_workflow_id ON jobs(workflow_id);
+CREATE UNIQUE INDEX idx_jobs_workflow_step ON jobs(workflow_id, step_name) WHERE workflow_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS job_dependencies (
+	job_id INT NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+	depends_on_job_id INT NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+	PRIMARY KEY (job_id, depends_on_job_id)
+);
+
+CREATE INDEX idx_job_dependencies_depends_on ON job_dependencies(depends_on_job_id);
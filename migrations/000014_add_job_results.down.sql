@@ -0,0 +1,2 @@
+ALTER TABLE jobs DROP COLUMN payload_template;
+ALTER TABLE jobs DROP COLUMN result;
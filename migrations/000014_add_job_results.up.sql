@@ -0,0 +1,5 @@
+ALTER TABLE jobs ADD COLUMN result JSONB;
+
+-- The payload as submitted, kept once its templates are filled in so a job
+-- released again reads the latest results
+ALTER TABLE jobs ADD COLUMN payload_template JSONB;
@@ -0,0 +1,3 @@
+ALTER TABLE job_logs DROP COLUMN attempt_id;
+
+DROP TABLE IF EXISTS job_attempts;
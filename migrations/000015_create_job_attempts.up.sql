@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS job_attempts (
+    id SERIAL PRIMARY KEY,
+    job_id INT NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+    attempt INT NOT NULL,
+    worker_id TEXT NOT NULL,
+    started_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    finished_at TIMESTAMP,
+    exit_code INT,
+    error TEXT,
+    duration_ms BIGINT
+);
+
+CREATE UNIQUE INDEX idx_job_attempts_job_id_attempt ON job_attempts(job_id, attempt);
+
+ALTER TABLE job_logs ADD COLUMN attempt_id INT REFERENCES job_attempts(id) ON DELETE SET NULL;
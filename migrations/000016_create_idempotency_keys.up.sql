@@ -0,0 +1,12 @@
+-- Keys are unique per queue, so clients of different queues can't collide
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    queue TEXT NOT NULL DEFAULT 'default',
+    key TEXT NOT NULL,
+    job_id INT REFERENCES jobs(id) ON DELETE CASCADE,
+    request_hash TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (queue, key)
+);
+
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_jobs_unique_key_active;
+
+ALTER TABLE jobs DROP COLUMN unique_key;
@@ -0,0 +1,5 @@
+ALTER TABLE jobs ADD COLUMN unique_key TEXT;
+
+-- At most one job per unique key may be waiting to run or running
+CREATE UNIQUE INDEX idx_jobs_unique_key_active ON jobs(unique_key)
+WHERE status IN ('scheduled', 'pending', 'in_progress', 'failed');
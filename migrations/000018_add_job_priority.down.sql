@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_jobs_pending_priority;
+
+ALTER TABLE jobs DROP COLUMN priority;
+
+DROP TYPE IF EXISTS job_priority;
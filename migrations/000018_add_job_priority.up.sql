@@ -0,0 +1,7 @@
+CREATE TYPE job_priority AS ENUM ('low', 'normal', 'high');
+
+ALTER TABLE jobs ADD COLUMN priority job_priority NOT NULL DEFAULT 'normal';
+
+-- Postgres workers claim the oldest pending job of one priority at a time
+CREATE INDEX idx_jobs_pending_priority ON jobs(priority, created_at)
+WHERE status = 'pending';
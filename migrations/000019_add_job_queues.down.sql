@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_jobs_pending_queue;
+
+ALTER TABLE jobs DROP COLUMN queue;
+
+CREATE INDEX idx_jobs_pending_priority ON jobs(priority, created_at)
+WHERE status = 'pending';
@@ -0,0 +1,6 @@
+ALTER TABLE jobs ADD COLUMN queue TEXT NOT NULL DEFAULT 'default';
+
+-- Postgres workers claim the oldest pending job of one queue and priority at a time
+DROP INDEX IF EXISTS idx_jobs_pending_priority;
+CREATE INDEX idx_jobs_pending_queue ON jobs(queue, priority, created_at)
+WHERE status = 'pending';
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS pauses;
+
+DROP TYPE IF EXISTS pause_scope;
@@ -0,0 +1,9 @@
+CREATE TYPE pause_scope AS ENUM ('queue', 'job_type');
+
+-- Pending jobs in a paused queue, or of a paused job type, are not started
+CREATE TABLE IF NOT EXISTS pauses (
+    scope pause_scope NOT NULL,
+    name TEXT NOT NULL,
+    paused_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (scope, name)
+);
@@ -0,0 +1,6 @@
+ALTER TABLE jobs DROP COLUMN throttles;
+ALTER TABLE jobs DROP COLUMN rate_limit_key;
+
+DROP TABLE IF EXISTS rate_limits;
+
+DROP TYPE IF EXISTS rate_limit_scope;
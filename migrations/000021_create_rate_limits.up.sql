@@ -0,0 +1,20 @@
+CREATE TYPE rate_limit_scope AS ENUM ('queue', 'job_type', 'key');
+
+-- Jobs may start at most rate times per period_seconds, with bursts of up to
+-- burst jobs, per queue, job type or rate limit key
+CREATE TABLE IF NOT EXISTS rate_limits (
+    scope rate_limit_scope NOT NULL,
+    name TEXT NOT NULL,
+    rate INT NOT NULL CHECK (rate > 0),
+    period_seconds INT NOT NULL CHECK (period_seconds > 0),
+    burst INT NOT NULL CHECK (burst > 0),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (scope, name)
+);
+
+ALTER TABLE jobs ADD COLUMN rate_limit_key TEXT;
+
+-- How many times in a row a job was put back to wait for a rate limit token,
+-- so only the first time is logged. Reset when the job starts.
+ALTER TABLE jobs ADD COLUMN throttles INT NOT NULL DEFAULT 0;
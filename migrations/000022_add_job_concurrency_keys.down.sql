@@ -0,0 +1,3 @@
+ALTER TABLE jobs DROP COLUMN slot_waits;
+ALTER TABLE jobs DROP COLUMN concurrency_limit;
+ALTER TABLE jobs DROP COLUMN concurrency_key;
@@ -0,0 +1,7 @@
+-- At most concurrency_limit jobs with the same concurrency_key run at a time
+ALTER TABLE jobs ADD COLUMN concurrency_key TEXT;
+ALTER TABLE jobs ADD COLUMN concurrency_limit INT CHECK (concurrency_limit > 0);
+
+-- How many times in a row a job was put back to wait for a concurrency slot,
+-- so the waits can back off. Reset when the job starts.
+ALTER TABLE jobs ADD COLUMN slot_waits INT NOT NULL DEFAULT 0;
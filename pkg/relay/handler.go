@@ -0,0 +1,75 @@
+package relay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/tomiwa-a/Relay/internal/executor"
+)
+
+// Handler runs a job in-process.
+// Returning nil completes the job, returning an error retries it up to its
+// max_retries, and returning an error wrapped with Permanent marks it as dead.
+type Handler interface {
+	Handle(ctx context.Context, payload json.RawMessage) error
+}
+
+// HandlerFunc adapts an ordinary function to the Handler interface
+type HandlerFunc func(ctx context.Context, payload json.RawMessage) error
+
+func (f HandlerFunc) Handle(ctx context.Context, payload json.RawMessage) error {
+	return f(ctx, payload)
+}
+
+// defaultRegistry holds the built-in executors and every handler registered by the embedding program
+var defaultRegistry = executor.NewDefaultRegistry()
+
+// Register runs h for jobs whose payload "type" matches jobType
+func Register(jobType string, h Handler) {
+	defaultRegistry.Register(jobType, &handlerExecutor{handler: h})
+}
+
+// Handle registers a typed handler for jobType.
+// The job payload is decoded into T before fn is called; payloads that cannot
+// be decoded fail permanently.
+func Handle[T any](jobType string, fn func(ctx context.Context, payload T) error) {
+	Register(jobType, HandlerFunc(func(ctx context.Context, raw json.RawMessage) error {
+		var payload T
+		if err := json.Unmarshal(raw, &payload); err != nil {
+			return Permanent(fmt.Errorf("invalid payload: %v", err))
+		}
+		return fn(ctx, payload)
+	}))
+}
+
+// Permanent wraps err so the job is marked as dead instead of being retried
+func Permanent(err error) error {
+	return executor.Permanent(err)
+}
+
+// handlerExecutor runs a Handler as an executor.Executor
+type handlerExecutor struct {
+	handler Handler
+}
+
+func (he *handlerExecutor) Execute(ctx context.Context, payload json.RawMessage) (result *executor.ExecutionResult, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			result = &executor.ExecutionResult{
+				ExitCode: 1,
+				Error:    fmt.Errorf("handler panicked: %v", r),
+			}
+			err = nil
+		}
+	}()
+
+	if err := he.handler.Handle(ctx, payload); err != nil {
+		return &executor.ExecutionResult{
+			ExitCode: 1,
+			Error:    err,
+		}, nil
+	}
+
+	return &executor.ExecutionResult{ExitCode: 0}, nil
+}
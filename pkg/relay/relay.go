@@ -0,0 +1,182 @@
+// Package relay embeds a Relay worker in another Go program so jobs can be
+// handled by in-process Go functions as well as the built-in executors.
+package relay
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/segmentio/kafka-go"
+	"github.com/tomiwa-a/Relay/internal/api/app"
+	"github.com/tomiwa-a/Relay/internal/outbox"
+	"github.com/tomiwa-a/Relay/internal/queue"
+	"github.com/tomiwa-a/Relay/internal/scheduler"
+	"github.com/tomiwa-a/Relay/internal/worker"
+)
+
+// Config holds the connection settings for an embedded worker.
+// Zero values fall back to the same defaults as the relay binary, except that
+// Redis is left out with the postgres queue backend unless RedisAddr is set.
+type Config struct {
+	DatabaseDSN     string
+	RedisAddr       string
+	LockTTL         time.Duration
+	DisableWatchdog bool
+	QueueBackend    string // "kafka" (default) or "postgres"
+	PollInterval    time.Duration
+	Concurrency     int            // jobs run at the same time per queue, defaults to 1
+	Queues          map[string]int // queues to consume and their concurrency (0 for Concurrency), defaults to the default queue
+	WorkerID        string         // recorded on job attempts, defaults to hostname-pid
+	DrainTimeout    time.Duration  // time running jobs get to finish once ctx is cancelled
+	KafkaBrokers    []string
+	KafkaTopic      string
+	KafkaGroupID    string
+	Logger          *log.Logger
+}
+
+// Run starts a worker that executes jobs with the registered handlers and
+// blocks until ctx is cancelled and the running jobs have drained
+func Run(ctx context.Context, cfg Config) error {
+	config := appConfig(cfg)
+
+	logger := cfg.Logger
+	if logger == nil {
+		logger = log.New(os.Stdout, "", log.Ldate|log.Ltime)
+	}
+
+	db, err := app.OpenDB(config.DB)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var redisClient *redis.Client
+	if config.Redis.Addr != "" {
+		redisClient = redis.NewClient(&redis.Options{
+			Addr: config.Redis.Addr,
+		})
+		defer redisClient.Close()
+	}
+
+	var publisher queue.Publisher
+
+	switch config.Queue.Backend {
+	case queue.BackendKafka:
+		kafkaWriter := &kafka.Writer{
+			Addr:                   kafka.TCP(config.Kafka.Brokers...),
+			Balancer:               &kafka.LeastBytes{},
+			AllowAutoTopicCreation: true,
+		}
+		defer kafkaWriter.Close()
+
+		publisher = &queue.KafkaPublisher{Writer: kafkaWriter, Topic: config.Kafka.Topic}
+	case queue.BackendPostgres:
+		publisher = &queue.PostgresPublisher{}
+	default:
+		return fmt.Errorf("relay: unknown queue backend: %s", config.Queue.Backend)
+	}
+
+	application := app.NewApplication(config, logger, db, publisher, redisClient)
+	application.Executors = defaultRegistry
+
+	consumers := make(map[string]queue.Consumer, len(config.Worker.Queues))
+	for name := range config.Worker.Queues {
+		if !queue.ValidQueueName(name) {
+			return fmt.Errorf("relay: invalid queue name: %q", name)
+		}
+
+		if config.Queue.Backend == queue.BackendPostgres {
+			consumers[name] = &queue.PostgresConsumer{
+				Repository:   application.Repository,
+				PollInterval: config.Queue.PollInterval,
+				Queue:        name,
+			}
+			continue
+		}
+
+		kafkaConsumer := &queue.KafkaConsumer{
+			Readers: queue.NewKafkaReaders(config.Kafka.Brokers, config.Kafka.Topic, name, config.Kafka.GroupID, true),
+		}
+		defer kafkaConsumer.Close()
+		consumers[name] = kafkaConsumer
+	}
+
+	// Retries scheduled by this worker are published through the outbox
+	go outbox.NewRelay(application).Start(ctx)
+	go worker.NewReaper(application).Start(ctx)
+	go scheduler.NewScheduler(application).Start(ctx)
+	go scheduler.NewCron(application).Start(ctx)
+
+	worker.NewWorker(application, consumers).Start(ctx)
+
+	return nil
+}
+
+func appConfig(cfg Config) app.Config {
+	var config app.Config
+
+	config.Env = "production"
+	config.DB.DSN = cfg.DatabaseDSN
+	config.DB.MaxOpenConns = 25
+	config.DB.MaxIdleConns = 25
+	config.DB.MaxIdleTime = "10m"
+
+	config.Outbox.PollInterval = 500 * time.Millisecond
+
+	config.Worker.ID = cfg.WorkerID
+	config.Worker.Concurrency = max(cfg.Concurrency, 1)
+	config.Worker.Queues = map[string]int{queue.DefaultQueue: config.Worker.Concurrency}
+	if len(cfg.Queues) > 0 {
+		config.Worker.Queues = make(map[string]int, len(cfg.Queues))
+		for name, concurrency := range cfg.Queues {
+			if concurrency <= 0 {
+				concurrency = config.Worker.Concurrency
+			}
+			config.Worker.Queues[name] = concurrency
+		}
+	}
+	config.Worker.DrainTimeout = cfg.DrainTimeout
+	if config.Worker.DrainTimeout <= 0 {
+		config.Worker.DrainTimeout = 30 * time.Second
+	}
+	config.Worker.HeartbeatInterval = 30 * time.Second
+	config.Reaper.Interval = time.Minute
+	config.Reaper.StaleAfter = 5 * time.Minute
+	config.Scheduler.PollInterval = time.Second
+
+	config.Queue.Backend = valueOr(cfg.QueueBackend, queue.BackendKafka)
+	config.Queue.PollInterval = cfg.PollInterval
+	if config.Queue.PollInterval <= 0 {
+		config.Queue.PollInterval = time.Second
+	}
+
+	config.Redis.Addr = cfg.RedisAddr
+	if config.Redis.Addr == "" && config.Queue.Backend == queue.BackendKafka {
+		config.Redis.Addr = "localhost:6379"
+	}
+	config.Redis.LockTTL = cfg.LockTTL
+	if config.Redis.LockTTL <= 0 {
+		config.Redis.LockTTL = 10 * time.Minute
+	}
+	config.Redis.UseWatchdog = !cfg.DisableWatchdog
+
+	config.Kafka.Brokers = cfg.KafkaBrokers
+	if len(config.Kafka.Brokers) == 0 {
+		config.Kafka.Brokers = []string{"localhost:9092"}
+	}
+	config.Kafka.Topic = valueOr(cfg.KafkaTopic, "relay-jobs")
+	config.Kafka.GroupID = valueOr(cfg.KafkaGroupID, "relay-worker-group")
+
+	return config
+}
+
+func valueOr(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}